@@ -2,9 +2,13 @@ package main
 
 import (
 	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
+	"io"
 	"os"
+	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
 
@@ -41,61 +45,227 @@ func formatPathWithCosts(g *graph.Graph, path []string, total int) string {
 }
 
 func main() {
-	dataPath := flag.String("data", "data/graph.json", "path to graph JSON file")
-	outPath := flag.String("out", "", "optional path to write results JSON; stdout only if empty")
-	flag.Parse()
+	if err := run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// run is the CLI entry point; it parses args, computes all pairs and writes the report to stdout.
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	fs := flag.NewFlagSet("pathroute", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	dataPath := fs.String("data", "data/graph.json", "path to graph JSON file")
+	outPath := fs.String("out", "", "optional path to write results JSON; stdout only if empty")
+	dotPath := fs.String("dot", "", "optional path to write the graph in Graphviz DOT format with shortest paths highlighted")
+	fromName := fs.String("from", "", "only report pairs starting at this node")
+	toName := fs.String("to", "", "only report pairs ending at this node")
+	fromStdin := fs.Bool("stdin", false, "read a \"from to cost\" edge list from stdin instead of -data")
+	trace := fs.Bool("trace", false, "print paths traceroute-style with cumulative distance per hop")
+	warnIsolated := fs.Bool("warn-isolated", false, "warn about nodes without outgoing or incoming edges, and about a disconnected graph, before the results")
+	format := fs.String("format", "text", "stdout format: text, csv or detailed (JSON with per-hop weights)")
+	sortNodes := fs.Bool("sort-nodes", false, "report pairs in sorted node-name order instead of graph order")
+	sortMode := fs.String("sort", floyd.SortByPair, "order of reported pairs: pair (node order) or distance (ascending, unreachable last)")
+	initSample := fs.Bool("init", false, "write a small sample graph to the -data path and exit")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *initSample {
+		if err := writeSampleGraph(*dataPath); err != nil {
+			return err
+		}
+		fmt.Fprintf(stderr, "Sample graph written to %s\n", *dataPath)
+		return nil
+	}
+	if *format != "text" && *format != "csv" && *format != "detailed" {
+		return fmt.Errorf("unknown -format %q (want text, csv or detailed)", *format)
+	}
+	if *sortMode != floyd.SortByPair && *sortMode != floyd.SortByDistance {
+		return fmt.Errorf("unknown -sort %q (want pair or distance)", *sortMode)
+	}
 
-	g, err := graph.NewFromJSON(*dataPath)
+	g, err := loadGraph(*dataPath, *fromStdin, stdin)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "load graph: %v\n", err)
-		os.Exit(1)
+		dataSet := false
+		fs.Visit(func(f *flag.Flag) { dataSet = dataSet || f.Name == "data" })
+		if !*fromStdin && !dataSet && errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("no graph at the default path %s: pass -data <file>, -stdin with an edge list, or run with -init to create a sample graph there", *dataPath)
+		}
+		return fmt.Errorf("load graph: %w", err)
+	}
+
+	if *warnIsolated {
+		if nodes := g.IsolatedSources(); len(nodes) > 0 {
+			fmt.Fprintf(stderr, "warning: nodes without outgoing edges (unreachable as source): %s\n", strings.Join(nodes, ", "))
+		}
+		if nodes := g.IsolatedSinks(); len(nodes) > 0 {
+			fmt.Fprintf(stderr, "warning: nodes without incoming edges (unreachable as destination): %s\n", strings.Join(nodes, ", "))
+		}
+		if !g.IsWeaklyConnected() {
+			fmt.Fprintln(stderr, "warning: graph is not connected, even ignoring edge direction")
+		}
 	}
 
 	r := floyd.RunFloyd(g)
 	r.FillViaNeighborPaths()
 
-	// Print to stdout
+	var selected []floyd.PairResult
 	for _, pr := range r.Results {
+		if (*fromName == "" || pr.From == *fromName) && (*toName == "" || pr.To == *toName) {
+			selected = append(selected, pr)
+		}
+	}
+	if *sortNodes {
+		rank := make(map[string]int)
+		for k, n := range g.SortedNodes() {
+			rank[n] = k
+		}
+		sort.SliceStable(selected, func(a, b int) bool {
+			pa, pb := selected[a], selected[b]
+			if pa.From != pb.From {
+				return rank[pa.From] < rank[pb.From]
+			}
+			return rank[pa.To] < rank[pb.To]
+		})
+	}
+	floyd.SortResults(selected, *sortMode)
+
+	switch *format {
+	case "csv":
+		if err := floyd.WriteResultsCSV(stdout, selected); err != nil {
+			return fmt.Errorf("write csv: %w", err)
+		}
+	case "detailed":
+		data, err := json.MarshalIndent(floyd.DetailedResults(g, selected), "", "  ")
+		if err != nil {
+			return fmt.Errorf("marshal results: %w", err)
+		}
+		fmt.Fprintf(stdout, "%s\n", data)
+	default:
+		printResults(stdout, g, selected, *trace)
+	}
+
+	if *dotPath != "" {
+		if err := writeDOTFile(*dotPath, g, selected); err != nil {
+			return err
+		}
+		fmt.Fprintf(stderr, "Graphviz written to %s\n", *dotPath)
+	}
+
+	if *outPath != "" {
+		type outStruct struct {
+			Pairs []floyd.PairResult `json:"pairs"`
+		}
+		enc := outStruct{Pairs: r.Results}
+		data, err := json.MarshalIndent(enc, "", "  ")
+		if err != nil {
+			return fmt.Errorf("marshal results: %w", err)
+		}
+		if err := os.WriteFile(*outPath, data, 0644); err != nil {
+			return fmt.Errorf("write %s: %w", *outPath, err)
+		}
+		fmt.Fprintf(stderr, "Results written to %s\n", *outPath)
+	}
+	return nil
+}
+
+// loadGraph reads the graph from the edge list on stdin if useStdin is set, else from the JSON file at path.
+func loadGraph(path string, useStdin bool, stdin io.Reader) (*graph.Graph, error) {
+	if !useStdin {
+		return graph.NewFromJSON(path)
+	}
+	gj, err := graph.ReadEdgeList(stdin)
+	if err != nil {
+		return nil, err
+	}
+	return graph.NewFromStruct(gj)
+}
+
+// printResults writes the human-readable report for results, skipping self-pairs. With trace set,
+// paths are rendered by floyd.FormatPathTrace instead of formatPathWithCosts.
+func printResults(w io.Writer, g *graph.Graph, results []floyd.PairResult, trace bool) {
+	format := func(p floyd.PathDist) string {
+		if trace {
+			return strings.ReplaceAll(floyd.FormatPathTrace(g, p.Path), "\n", "\n    ")
+		}
+		return formatPathWithCosts(g, p.Path, p.Distance)
+	}
+	for _, pr := range results {
 		if pr.From == pr.To {
 			continue
 		}
 		if pr.Distance < 0 {
-			fmt.Printf("%s -> %s: no path\n", pr.From, pr.To)
+			fmt.Fprintf(w, "%s -> %s: no path\n", pr.From, pr.To)
 			continue
 		}
-		fmt.Printf("%s -> %s", pr.From, pr.To)
+		fmt.Fprintf(w, "%s -> %s", pr.From, pr.To)
 		if len(pr.Paths) > 0 {
-			fmt.Printf(", shortest distance: %d, paths (top 4, got %d):\n", pr.Paths[0].Distance, len(pr.Paths))
+			fmt.Fprintf(w, ", shortest distance: %d, paths (top 4, got %d):\n", pr.Paths[0].Distance, len(pr.Paths))
 			for _, p := range pr.Paths {
-				fmt.Printf("    %s\n", formatPathWithCosts(g, p.Path, p.Distance))
+				fmt.Fprintf(w, "    %s\n", format(p))
 			}
 		} else {
-			fmt.Println()
+			fmt.Fprintln(w)
 		}
 		if len(pr.ViaNeighborPaths) > 0 {
-			fmt.Printf("  via-neighbor paths(%d):\n", len(pr.ViaNeighborPaths))
+			fmt.Fprintf(w, "  via-neighbor paths(%d):\n", len(pr.ViaNeighborPaths))
 			for _, v := range pr.ViaNeighborPaths {
-				fmt.Printf("    %s\n", formatPathWithCosts(g, v.Path, v.Distance))
+				fmt.Fprintf(w, "    %s\n", format(v))
 			}
 		}
 	}
+}
 
-	if *outPath != "" {
-		type outStruct struct {
-			Pairs []floyd.PairResult `json:"pairs"`
-		}
-		enc := outStruct{Pairs: r.Results}
-		data, err := json.MarshalIndent(enc, "", "  ")
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "marshal results: %v\n", err)
-			os.Exit(1)
+// sampleGraphJSON is the graph written by -init: a small triangle with a cheaper detour.
+const sampleGraphJSON = `{
+  "nodes": ["A", "B", "C"],
+  "edges": [
+    {"from": "A", "to": "B", "cost": 50, "des": "A to B"},
+    {"from": "B", "to": "C", "cost": 20, "des": "B to C"},
+    {"from": "A", "to": "C", "cost": 100, "des": "A to C"},
+    {"from": "C", "to": "A", "cost": 30, "des": "C to A"}
+  ]
+}
+`
+
+// writeSampleGraph writes sampleGraphJSON to path, creating its directory; an existing file is
+// never overwritten.
+func writeSampleGraph(path string) error {
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("write %s: %w", path, err)
 		}
-		if err := os.WriteFile(*outPath, data, 0644); err != nil {
-			fmt.Fprintf(os.Stderr, "write %s: %v\n", *outPath, err)
-			os.Exit(1)
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		return fmt.Errorf("write %s: %w", path, err)
+	}
+	if _, err := io.WriteString(f, sampleGraphJSON); err != nil {
+		f.Close()
+		return fmt.Errorf("write %s: %w", path, err)
+	}
+	return f.Close()
+}
+
+// writeDOTFile writes g as DOT to path, highlighting the shortest (minimum-distance) paths of results.
+func writeDOTFile(path string, g *graph.Graph, results []floyd.PairResult) error {
+	var highlight [][]string
+	for _, pr := range results {
+		for _, p := range pr.Paths {
+			if pr.From != pr.To && p.Distance == pr.Distance {
+				highlight = append(highlight, p.Path)
+			}
 		}
-		fmt.Fprintf(os.Stderr, "Results written to %s\n", *outPath)
 	}
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("write %s: %w", path, err)
+	}
+	if err := graph.WriteDOT(f, g, highlight); err != nil {
+		f.Close()
+		return fmt.Errorf("write %s: %w", path, err)
+	}
+	return f.Close()
 }
 
 /*
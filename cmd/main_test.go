@@ -0,0 +1,189 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/jursonmo/pathroute/floyd"
+	"github.com/jursonmo/pathroute/graph"
+)
+
+const testGraphJSON = `{"nodes":["A","B","C"],"edges":[` +
+	`{"from":"A","to":"B","cost":50},{"from":"B","to":"C","cost":20},{"from":"A","to":"C","cost":100}]}`
+
+// writeTestGraph writes testGraphJSON into a temp dir and returns its path.
+func writeTestGraph(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "graph.json")
+	if err := os.WriteFile(path, []byte(testGraphJSON), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestRun_DOT(t *testing.T) {
+	data := writeTestGraph(t)
+	dot := filepath.Join(t.TempDir(), "out.gv")
+	var stdout, stderr bytes.Buffer
+	if err := run([]string{"-data", data, "-dot", dot, "-from", "A", "-to", "C"}, nil, &stdout, &stderr); err != nil {
+		t.Fatal(err)
+	}
+	b, err := os.ReadFile(dot)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := string(b)
+	if !strings.HasPrefix(out, "digraph") {
+		t.Errorf("DOT file should start with digraph header:\n%s", out)
+	}
+	if !strings.Contains(out, `"A" -> "B" [label="50", color=red, penwidth=2];`) {
+		t.Errorf("A->B on the A->C shortest path should be highlighted:\n%s", out)
+	}
+	if !strings.Contains(out, `"A" -> "C" [label="100"];`) {
+		t.Errorf("direct A->C is not a shortest path and should not be highlighted:\n%s", out)
+	}
+	if !strings.Contains(stdout.String(), "A -> C, shortest distance: 70") || strings.Contains(stdout.String(), "A -> B") {
+		t.Errorf("text output should only report A -> C:\n%s", stdout.String())
+	}
+}
+
+func TestRun_WarnIsolated(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	if err := run([]string{"-data", writeTestGraph(t), "-warn-isolated"}, nil, &stdout, &stderr); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(stderr.String(), "without outgoing edges (unreachable as source): C") ||
+		!strings.Contains(stderr.String(), "without incoming edges (unreachable as destination): A") {
+		t.Errorf("missing isolation warnings:\n%s", stderr.String())
+	}
+	if strings.Contains(stderr.String(), "not connected") {
+		t.Errorf("test graph is connected:\n%s", stderr.String())
+	}
+
+	stderr.Reset()
+	in := strings.NewReader("A B 1\nC D 1\n")
+	if err := run([]string{"-stdin", "-warn-isolated"}, in, &stdout, &stderr); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(stderr.String(), "warning: graph is not connected") {
+		t.Errorf("missing connectivity warning:\n%s", stderr.String())
+	}
+}
+
+func TestRun_Stdin(t *testing.T) {
+	in := strings.NewReader("# ad-hoc\nA B 50\n\nB C 20\nA C 100\n")
+	var stdout, stderr bytes.Buffer
+	if err := run([]string{"-stdin"}, in, &stdout, &stderr); err != nil {
+		t.Fatal(err)
+	}
+	out := stdout.String()
+	if !strings.Contains(out, "A -> B, shortest distance: 50") || !strings.Contains(out, "A -> C, shortest distance: 70") {
+		t.Errorf("unexpected output:\n%s", out)
+	}
+}
+
+func TestRun_Trace(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	if err := run([]string{"-data", writeTestGraph(t), "-trace", "-from", "A", "-to", "C"}, nil, &stdout, &stderr); err != nil {
+		t.Fatal(err)
+	}
+	want := "    1  A  (+0, total 0)\n    2  B  (+50, total 50)\n    3  C  (+20, total 70)\n"
+	if !strings.Contains(stdout.String(), want) {
+		t.Errorf("trace output missing:\n%s\ngot:\n%s", want, stdout.String())
+	}
+}
+
+func TestRun_FormatCSV(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	if err := run([]string{"-data", writeTestGraph(t), "-format", "csv", "-from", "A"}, nil, &stdout, &stderr); err != nil {
+		t.Fatal(err)
+	}
+	want := "from,to,distance,hops,path\nA,B,50,1,A->B\nA,C,70,2,A->B->C\n"
+	if stdout.String() != want {
+		t.Errorf("got:\n%s\nwant:\n%s", stdout.String(), want)
+	}
+	if err := run([]string{"-data", writeTestGraph(t), "-format", "xml"}, nil, &stdout, &stderr); err == nil {
+		t.Error("expected error for unknown -format")
+	}
+}
+
+func TestRun_FormatDetailed(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	if err := run([]string{"-data", writeTestGraph(t), "-format", "detailed", "-from", "A", "-to", "C"}, nil, &stdout, &stderr); err != nil {
+		t.Fatal(err)
+	}
+	var pairs []floyd.DetailedPair
+	if err := json.Unmarshal(stdout.Bytes(), &pairs); err != nil {
+		t.Fatalf("unmarshal %q: %v", stdout.String(), err)
+	}
+	if len(pairs) != 1 || len(pairs[0].Paths) == 0 {
+		t.Fatalf("got %+v, want one A->C pair with paths", pairs)
+	}
+	p := pairs[0].Paths[0]
+	want := []floyd.Hop{{From: "A", To: "B", Weight: 50}, {From: "B", To: "C", Weight: 20}}
+	if p.Distance != 70 || !reflect.DeepEqual(p.Hops, want) {
+		t.Errorf("got distance %d hops %+v, want 70 %+v", p.Distance, p.Hops, want)
+	}
+}
+
+func TestRun_Init(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data", "graph.json")
+	var stdout, stderr bytes.Buffer
+	if err := run([]string{"-init", "-data", path}, nil, &stdout, &stderr); err != nil {
+		t.Fatal(err)
+	}
+	g, err := graph.NewFromJSON(path)
+	if err != nil {
+		t.Fatalf("sample graph does not load: %v", err)
+	}
+	if g.NumNodes() != 3 {
+		t.Errorf("sample graph has %d nodes, want 3", g.NumNodes())
+	}
+	if err := run([]string{"-init", "-data", path}, nil, &stdout, &stderr); err == nil {
+		t.Error("-init should not overwrite an existing file")
+	}
+	if err := run([]string{"-data", path, "-from", "A", "-to", "C", "-format", "csv"}, nil, &stdout, &stderr); err != nil {
+		t.Errorf("running on the sample graph: %v", err)
+	}
+}
+
+func TestRun_MissingDefaultData(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	// Tests run in the package directory, which has no data/graph.json.
+	err := run(nil, nil, &stdout, &stderr)
+	if err == nil || !strings.Contains(err.Error(), "-init") {
+		t.Errorf("expected a hint about -init, got %v", err)
+	}
+	err = run([]string{"-data", "missing.json"}, nil, &stdout, &stderr)
+	if err == nil || strings.Contains(err.Error(), "-init") {
+		t.Errorf("an explicit -data should report the raw error, got %v", err)
+	}
+}
+
+func TestRun_SortDistance(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	if err := run([]string{"-data", writeTestGraph(t), "-format", "csv", "-sort", "distance"}, nil, &stdout, &stderr); err != nil {
+		t.Fatal(err)
+	}
+	want := "from,to,distance,hops,path\nB,C,20,1,B->C\nA,B,50,1,A->B\nA,C,70,2,A->B->C\n"
+	if !strings.HasPrefix(stdout.String(), want) {
+		t.Errorf("got:\n%s\nwant prefix:\n%s", stdout.String(), want)
+	}
+}
+
+func TestRun_SortNodes(t *testing.T) {
+	in := strings.NewReader("C A 5\nB C 1\n")
+	var stdout, stderr bytes.Buffer
+	if err := run([]string{"-stdin", "-format", "csv", "-sort-nodes"}, in, &stdout, &stderr); err != nil {
+		t.Fatal(err)
+	}
+	want := "from,to,distance,hops,path\nA,B,-1,0,\nA,C,-1,0,\nB,A,6,2,B->C->A\nB,C,1,1,B->C\nC,A,5,1,C->A\nC,B,-1,0,\n"
+	if stdout.String() != want {
+		t.Errorf("got:\n%s\nwant:\n%s", stdout.String(), want)
+	}
+}
@@ -0,0 +1,149 @@
+package floyd
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/jursonmo/pathroute/graph"
+)
+
+// MinArborescence returns the minimum-cost spanning arborescence of g rooted at root: one
+// incoming edge for every other node such that all nodes are reachable from root, with the
+// smallest total cost (Chu-Liu/Edmonds algorithm). Edges are returned ordered by the index of
+// their To node, along with the total cost. It errors if root is unknown or some node is
+// unreachable from root.
+func MinArborescence(g *graph.Graph, root string) ([]graph.Edge, int, error) {
+	r, ok := g.Index(root)
+	if !ok {
+		return nil, 0, fmt.Errorf("unknown node %q", root)
+	}
+	adj := g.CostMatrix()
+	N := len(adj)
+	var edges []arbEdge
+	for i := range adj {
+		for j, w := range adj[i] {
+			if w != 0 && i != j {
+				edges = append(edges, arbEdge{i, j, w})
+			}
+		}
+	}
+	sel, ok := arborescence(N, r, edges)
+	if !ok {
+		return nil, 0, fmt.Errorf("not every node is reachable from %s", root)
+	}
+	sort.Slice(sel, func(a, b int) bool { return edges[sel[a]].v < edges[sel[b]].v })
+	out := make([]graph.Edge, len(sel))
+	total := 0
+	for k, s := range sel {
+		e := edges[s]
+		out[k] = graph.Edge{From: g.Name(e.u), To: g.Name(e.v), Cost: e.w}
+		total += e.w
+	}
+	return out, total, nil
+}
+
+// arbEdge is a directed edge u->v of weight w used by arborescence.
+type arbEdge struct{ u, v, w int }
+
+// arborescence returns the indices into edges of a minimum arborescence over n nodes rooted at
+// root, or false if some node has no path from root. Every node picks its cheapest incoming
+// edge; if these form cycles, each cycle is contracted to one node, edges entering it are
+// reduced by the cost of the cycle edge they would replace, and the smaller problem is solved
+// recursively. Expanding a cycle keeps all its edges except the one into the node where the
+// chosen entering edge arrives.
+func arborescence(n, root int, edges []arbEdge) ([]int, bool) {
+	in := make([]int, n)
+	for v := range in {
+		in[v] = -1
+	}
+	for k, e := range edges {
+		if e.u == e.v || e.v == root {
+			continue
+		}
+		if in[e.v] < 0 || e.w < edges[in[e.v]].w {
+			in[e.v] = k
+		}
+	}
+	for v := 0; v < n; v++ {
+		if v != root && in[v] < 0 {
+			return nil, false
+		}
+	}
+	// Find the cycles formed by the chosen in-edges; comp numbers cycles first.
+	comp := make([]int, n)
+	mark := make([]int, n)
+	for v := range comp {
+		comp[v], mark[v] = -1, -1
+	}
+	var cycles [][]int
+	for v := 0; v < n; v++ {
+		x := v
+		for x != root && mark[x] < 0 {
+			mark[x] = v
+			x = edges[in[x]].u
+		}
+		if x == root || mark[x] != v || comp[x] >= 0 {
+			continue
+		}
+		var cycle []int
+		for y := x; ; {
+			comp[y] = len(cycles)
+			cycle = append(cycle, y)
+			if y = edges[in[y]].u; y == x {
+				break
+			}
+		}
+		cycles = append(cycles, cycle)
+	}
+	if len(cycles) == 0 {
+		sel := make([]int, 0, n-1)
+		for v := 0; v < n; v++ {
+			if v != root {
+				sel = append(sel, in[v])
+			}
+		}
+		return sel, true
+	}
+	numCycles, next := len(cycles), len(cycles)
+	for v := range comp {
+		if comp[v] < 0 {
+			comp[v] = next
+			next++
+		}
+	}
+	var contracted []arbEdge
+	var prev []int // prev[k] = index in edges of contracted[k]
+	for k, e := range edges {
+		cu, cv := comp[e.u], comp[e.v]
+		if cu == cv {
+			continue
+		}
+		w := e.w
+		if cv < numCycles {
+			w -= edges[in[e.v]].w
+		}
+		contracted = append(contracted, arbEdge{cu, cv, w})
+		prev = append(prev, k)
+	}
+	sub, ok := arborescence(next, comp[root], contracted)
+	if !ok {
+		return nil, false
+	}
+	entry := make([]int, numCycles)
+	sel := make([]int, 0, n-1)
+	for _, s := range sub {
+		k := prev[s]
+		sel = append(sel, k)
+		if c := comp[edges[k].v]; c < numCycles {
+			entry[c] = edges[k].v
+		}
+	}
+	for c, cycle := range cycles {
+		for _, y := range cycle {
+			if y != entry[c] {
+				sel = append(sel, in[y])
+			}
+		}
+	}
+	return sel, true
+}
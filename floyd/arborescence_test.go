@@ -0,0 +1,55 @@
+package floyd
+
+import (
+	"testing"
+
+	"github.com/jursonmo/pathroute/graph"
+)
+
+func TestMinArborescence(t *testing.T) {
+	// The cheapest in-edges of A, B and C form the cycle A->B->C->A; the optimum breaks it by
+	// entering at A from R (cost 10, replacing C->A of cost 1): R->A, A->B, B->C, C->D = 10+1+1+2.
+	g, err := graph.NewFromStruct(&graph.GraphJSON{Edges: []graph.Edge{
+		{From: "R", To: "A", Cost: 10},
+		{From: "R", To: "B", Cost: 12},
+		{From: "R", To: "C", Cost: 20},
+		{From: "A", To: "B", Cost: 1},
+		{From: "B", To: "C", Cost: 1},
+		{From: "C", To: "A", Cost: 1},
+		{From: "C", To: "D", Cost: 2},
+		{From: "A", To: "D", Cost: 5},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	edges, total, err := MinArborescence(g, "R")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total != 14 {
+		t.Errorf("total = %d, want 14 (edges %v)", total, edges)
+	}
+	if len(edges) != g.NumNodes()-1 {
+		t.Fatalf("got %d edges, want %d", len(edges), g.NumNodes()-1)
+	}
+	parent := make(map[string]string)
+	for _, e := range edges {
+		if _, dup := parent[e.To]; dup {
+			t.Errorf("%s has two incoming edges", e.To)
+		}
+		parent[e.To] = e.From
+	}
+	for _, n := range []string{"A", "B", "C", "D"} {
+		x, steps := n, 0
+		for x != "R" && steps <= len(edges) {
+			x = parent[x]
+			steps++
+		}
+		if x != "R" {
+			t.Errorf("%s does not reach the root", n)
+		}
+	}
+	if _, _, err := MinArborescence(g, "D"); err == nil {
+		t.Error("expected error when nodes are unreachable from the root")
+	}
+}
@@ -0,0 +1,43 @@
+package floyd
+
+import (
+	"fmt"
+
+	"github.com/jursonmo/pathroute/graph"
+)
+
+// ShortestPathAvoidingEdges returns the shortest path from -> to that uses none of the directed
+// edges in avoid (each a {from, to} pair). The edges are zeroed in a copy of the cost matrix, so
+// g is not modified; avoiding an edge that does not exist is a no-op. It errors if any name is
+// unknown or to is unreachable without the avoided edges.
+func ShortestPathAvoidingEdges(g *graph.Graph, from, to string, avoid [][2]string) (PathDist, error) {
+	s, ok := g.Index(from)
+	if !ok {
+		return PathDist{}, fmt.Errorf("unknown node %q", from)
+	}
+	t, ok := g.Index(to)
+	if !ok {
+		return PathDist{}, fmt.Errorf("unknown node %q", to)
+	}
+	adj := g.CostMatrix()
+	for _, e := range avoid {
+		i, ok := g.Index(e[0])
+		if !ok {
+			return PathDist{}, fmt.Errorf("unknown node %q in avoided edge %s -> %s", e[0], e[0], e[1])
+		}
+		j, ok := g.Index(e[1])
+		if !ok {
+			return PathDist{}, fmt.Errorf("unknown node %q in avoided edge %s -> %s", e[1], e[0], e[1])
+		}
+		adj[i][j] = 0
+	}
+	cost := withNodeWeights(func(i, j int) int { return adj[i][j] }, s, nodeWeightVector(g))
+	dist, parent := dijkstraTree(len(adj), s, func(i, j int) (int, bool) {
+		w := cost(i, j)
+		return w, w != 0
+	}, nonTransitMask(g))
+	if dist[t] == Inf {
+		return PathDist{}, fmt.Errorf("%s is unreachable from %s without the avoided edges", to, from)
+	}
+	return PathDist{Path: treePath(g, parent, s, t), Distance: dist[t]}, nil
+}
@@ -0,0 +1,33 @@
+package floyd
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestShortestPathAvoidingEdges(t *testing.T) {
+	g := weightedGraph(t) // A->B 50, B->A 80, A->C 100, B->C 20
+	p, err := ShortestPathAvoidingEdges(g, "A", "C", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Distance != 70 || !reflect.DeepEqual(p.Path, []string{"A", "B", "C"}) {
+		t.Errorf("no avoidance: got %v", p)
+	}
+	p, err = ShortestPathAvoidingEdges(g, "A", "C", [][2]string{{"B", "C"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Distance != 100 || !reflect.DeepEqual(p.Path, []string{"A", "C"}) {
+		t.Errorf("avoiding B->C: got %v, want direct A->C 100", p)
+	}
+	if g.Cost(1, 2) != 20 {
+		t.Error("graph was modified")
+	}
+	if _, err := ShortestPathAvoidingEdges(g, "A", "C", [][2]string{{"B", "C"}, {"A", "C"}}); err == nil {
+		t.Error("expected error when every route is avoided")
+	}
+	if _, err := ShortestPathAvoidingEdges(g, "A", "C", [][2]string{{"B", "X"}}); err == nil {
+		t.Error("expected error for unknown edge endpoint")
+	}
+}
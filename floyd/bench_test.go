@@ -0,0 +1,61 @@
+package floyd
+
+import (
+	"testing"
+
+	"github.com/jursonmo/pathroute/graph"
+)
+
+func BenchmarkFillViaNeighborPaths_150(b *testing.B) {
+	g := graph.RandomGraph(150, 450, 50, 1)
+	// Skip RunFloyd's k-shortest enumeration, which is not what is measured here.
+	dist, pred := floydWarshall(g)
+	N := g.NumNodes()
+	results := make([]PairResult, 0, N*N)
+	for i := 0; i < N; i++ {
+		for j := 0; j < N; j++ {
+			results = append(results, newPairResult(g, i, j, dist[i][j], nil))
+		}
+	}
+	r := &AllPairsResult{Results: results, g: g, dist: dist, pred: pred}
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		r.FillViaNeighborPaths()
+	}
+}
+
+// BenchmarkPathStorage_150 compares the memory of the predecessor lists with the single first-hop
+// matrix kept by Options.SinglePathOnly.
+func BenchmarkPathStorage_150(b *testing.B) {
+	g := graph.RandomGraph(150, 450, 50, 1)
+	b.Run("Pred", func(b *testing.B) {
+		b.ReportAllocs()
+		for n := 0; n < b.N; n++ {
+			floydWarshall(g)
+		}
+	})
+	b.Run("NextHop", func(b *testing.B) {
+		b.ReportAllocs()
+		for n := 0; n < b.N; n++ {
+			floydNextHops(g, Inf)
+		}
+	})
+}
+
+// BenchmarkAllPairs_150 compares Floyd-Warshall with the per-source Dijkstra searches of
+// RunJohnson, which run in parallel.
+func BenchmarkAllPairs_150(b *testing.B) {
+	g := graph.RandomGraph(150, 1100, 100, 1)
+	b.Run("Floyd", func(b *testing.B) {
+		for n := 0; n < b.N; n++ {
+			floydDistances(g)
+		}
+	})
+	b.Run("Johnson", func(b *testing.B) {
+		for n := 0; n < b.N; n++ {
+			if _, err := RunJohnson(g); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
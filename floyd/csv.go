@@ -0,0 +1,34 @@
+package floyd
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// WriteResultsCSV writes results as CSV with the header from,to,distance,hops,path. path is the
+// first shortest path joined by "->" and hops its edge count; unreachable pairs have distance -1,
+// hops 0 and an empty path. Self-pairs are skipped.
+func WriteResultsCSV(w io.Writer, results []PairResult) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"from", "to", "distance", "hops", "path"}); err != nil {
+		return err
+	}
+	for _, pr := range results {
+		if pr.From == pr.To {
+			continue
+		}
+		hops, path := 0, ""
+		if len(pr.Paths) > 0 {
+			hops = len(pr.Paths[0].Path) - 1
+			path = strings.Join(pr.Paths[0].Path, "->")
+		}
+		row := []string{pr.From, pr.To, strconv.Itoa(pr.Distance), strconv.Itoa(hops), path}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,37 @@
+package floyd
+
+import (
+	"bytes"
+	"encoding/csv"
+	"reflect"
+	"testing"
+)
+
+func TestWriteResultsCSV(t *testing.T) {
+	r := RunFloyd(weightedGraph(t))
+	var buf bytes.Buffer
+	if err := WriteResultsCSV(&buf, r.Results); err != nil {
+		t.Fatal(err)
+	}
+	rows, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// header + 3*2 non-self pairs
+	if len(rows) != 7 {
+		t.Fatalf("got %d rows, want 7: %v", len(rows), rows)
+	}
+	if want := []string{"from", "to", "distance", "hops", "path"}; !reflect.DeepEqual(rows[0], want) {
+		t.Errorf("header = %v", rows[0])
+	}
+	byPair := make(map[string][]string)
+	for _, row := range rows[1:] {
+		byPair[row[0]+row[1]] = row
+	}
+	if got, want := byPair["AC"], []string{"A", "C", "70", "2", "A->B->C"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("A->C row = %v, want %v", got, want)
+	}
+	if got, want := byPair["CA"], []string{"C", "A", "-1", "0", ""}; !reflect.DeepEqual(got, want) {
+		t.Errorf("C->A row = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,66 @@
+package floyd
+
+import "slices"
+
+// RouteChange describes how the shortest route of one pair changed between two results.
+// Distances are Unreachable and paths nil when the pair is unreachable (or absent) on that side.
+type RouteChange struct {
+	From        string   `json:"from"`
+	To          string   `json:"to"`
+	OldDistance int      `json:"old_distance"`
+	NewDistance int      `json:"new_distance"`
+	OldPath     []string `json:"old_path"`
+	NewPath     []string `json:"new_path"`
+}
+
+// DiffResults returns the pairs whose shortest distance or first shortest path differs between
+// old and new, in new's Results order followed by pairs that only exist in old. Self-pairs are skipped.
+func DiffResults(old, new *AllPairsResult) []RouteChange {
+	oldByPair := make(map[[2]string]PairResult, len(old.Results))
+	for _, pr := range old.Results {
+		oldByPair[[2]string{pr.From, pr.To}] = pr
+	}
+	var changes []RouteChange
+	for _, np := range new.Results {
+		if np.From == np.To {
+			continue
+		}
+		key := [2]string{np.From, np.To}
+		op, ok := oldByPair[key]
+		delete(oldByPair, key)
+		if !ok {
+			op = PairResult{From: np.From, To: np.To, Distance: Unreachable}
+		}
+		if c, changed := routeChange(op, np); changed {
+			changes = append(changes, c)
+		}
+	}
+	for _, op := range old.Results {
+		if _, ok := oldByPair[[2]string{op.From, op.To}]; !ok || op.From == op.To {
+			continue
+		}
+		if c, changed := routeChange(op, PairResult{From: op.From, To: op.To, Distance: Unreachable}); changed {
+			changes = append(changes, c)
+		}
+	}
+	return changes
+}
+
+func routeChange(op, np PairResult) (RouteChange, bool) {
+	c := RouteChange{
+		From:        np.From,
+		To:          np.To,
+		OldDistance: op.Distance,
+		NewDistance: np.Distance,
+		OldPath:     firstPath(op),
+		NewPath:     firstPath(np),
+	}
+	return c, c.OldDistance != c.NewDistance || !slices.Equal(c.OldPath, c.NewPath)
+}
+
+func firstPath(pr PairResult) []string {
+	if len(pr.Paths) == 0 {
+		return nil
+	}
+	return pr.Paths[0].Path
+}
@@ -0,0 +1,27 @@
+package floyd
+
+import "testing"
+
+func TestDiffResults(t *testing.T) {
+	g := weightedGraph(t)
+	base := RunFloyd(g)
+	g2 := g.Clone()
+	if err := g2.RemoveEdge("B", "C"); err != nil {
+		t.Fatal(err)
+	}
+	changes := DiffResults(base, RunFloyd(g2))
+	// A->C: 70 via B -> 100 direct; B->C: 20 direct -> 180 via A.
+	if len(changes) != 2 {
+		t.Fatalf("expected 2 changes, got %d: %+v", len(changes), changes)
+	}
+	want := map[string][2]int{"AC": {70, 100}, "BC": {20, 180}}
+	for _, c := range changes {
+		w, ok := want[c.From+c.To]
+		if !ok || c.OldDistance != w[0] || c.NewDistance != w[1] {
+			t.Errorf("unexpected change %+v", c)
+		}
+	}
+	if len(DiffResults(base, RunFloyd(g))) != 0 {
+		t.Error("identical results should produce no changes")
+	}
+}
@@ -0,0 +1,53 @@
+package floyd
+
+// dijkstra returns single-source distances from src over n nodes using an O(n²)
+// array-based Dijkstra, which suits the dense adjacency-matrix representation.
+// cost(i, j) is the weight of edge i->j, or <= 0 if there is no edge. Unreachable
+// nodes get Inf. Nodes other than src with skip set (may be nil) are not passed through.
+func dijkstra(n, src int, cost func(i, j int) int, skip []bool) []int {
+	dist, _ := dijkstraTree(n, src, func(i, j int) (int, bool) {
+		w := cost(i, j)
+		return w, w > 0
+	}, skip)
+	return dist
+}
+
+// dijkstraTree is dijkstra over an explicit edge function, which reports the weight of i->j
+// (must be >= 0) and whether the edge exists. It also returns the parent of each node on its
+// shortest path (-1 for src and unreachable nodes); ties keep the first parent found. Nodes
+// other than src with skip set (may be nil) are reached but not relaxed from.
+func dijkstraTree(n, src int, edge func(i, j int) (int, bool), skip []bool) (dist, parent []int) {
+	dist = make([]int, n)
+	parent = make([]int, n)
+	done := make([]bool, n)
+	for i := range dist {
+		dist[i] = Inf
+		parent[i] = -1
+	}
+	dist[src] = 0
+	for {
+		u := -1
+		for i := 0; i < n; i++ {
+			if !done[i] && dist[i] != Inf && (u < 0 || dist[i] < dist[u]) {
+				u = i
+			}
+		}
+		if u < 0 {
+			return dist, parent
+		}
+		done[u] = true
+		if u != src && skip != nil && skip[u] {
+			continue
+		}
+		for v := 0; v < n; v++ {
+			w, ok := edge(u, v)
+			if !ok || done[v] {
+				continue
+			}
+			if d := dist[u] + w; d < dist[v] {
+				dist[v] = d
+				parent[v] = u
+			}
+		}
+	}
+}
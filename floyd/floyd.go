@@ -2,24 +2,49 @@ package floyd
 
 import (
 	"container/heap"
+	"fmt"
 	"math"
+	"math/rand"
+	"sort"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/jursonmo/pathroute/graph"
 )
 
+// Inf is the internal distance of an unreachable pair. Exported results never contain it: they
+// report Unreachable instead.
 const Inf = math.MaxInt
 
+// Unreachable is the distance reported for a pair with no path (PairResult.Distance,
+// DistanceMatrix, Query, ...).
+const Unreachable = -1
+
+// IsReachable reports whether d, an exported (or internal) distance, denotes a reachable pair.
+// Results of RunJohnson may contain negative distances, including -1; use CanReach for those.
+func IsReachable(d int) bool {
+	return d != Unreachable && d != Inf
+}
+
+// Default path caps; override them per run with Options.Limits or per graph with graph.Options.Limits.
 const (
-	MaxShortestPaths    = 4
-	MaxViaNeighborPaths = 3
+	MaxShortestPaths    = graph.DefaultMaxShortestPaths
+	MaxViaNeighborPaths = graph.DefaultMaxViaNeighborPaths
 )
 
 // PairResult holds shortest distance and up to MaxShortestPaths paths for one (From, To).
 // Paths are sorted by total distance (1st, 2nd, ... shortest); distances may differ.
+//
+// Ordering is deterministic: for a fixed graph, every run yields identical Results. Results are
+// in From-index-major order (i*N+j); paths of equal distance are ordered by their sequence of
+// node indices, compared lexicographically (a shorter prefix first); ViaNeighborPaths of equal
+// distance keep the order of S's out-neighbors by index, then the predecessor order (ascending
+// index) of the sub-path enumeration.
 type PairResult struct {
 	From     string     `json:"from"`
 	To       string     `json:"to"`
-	Distance int        `json:"distance"` // 1st shortest distance, or -1 if unreachable
+	Distance int        `json:"distance"` // 1st shortest distance, or Unreachable (-1)
 	Paths    []PathDist `json:"paths"`    // at most MaxShortestPaths, each with its own distance
 	// ViaNeighborPaths: paths S -> N -> ... -> D that do not contain S (except start); at most MaxViaNeighborPaths
 	ViaNeighborPaths []PathDist `json:"via_neighbor_paths,omitempty"`
@@ -29,6 +54,8 @@ type PairResult struct {
 type PathDist struct {
 	Path     []string `json:"path"`
 	Distance int      `json:"distance"`
+	// Metrics holds the accumulated value of each metric RunFloydBy did not optimize for.
+	Metrics map[string]int `json:"metrics,omitempty"`
 }
 
 // AllPairsResult holds results for all pairs and the graph (for via-neighbor computation).
@@ -37,87 +64,369 @@ type AllPairsResult struct {
 	g       *graph.Graph
 	dist    [][]int
 	pred    [][][]int // pred[i][j] = list of predecessors k on shortest i->j path (dist[i][k]+w(k,j)==dist[i][j])
+	// predOnce guards the lazy build of pred for DistancesOnly and SinglePathOnly results; see
+	// predecessors.
+	predOnce sync.Once
+	// distancesOnly is set when Paths were not enumerated (Options.DistancesOnly).
+	distancesOnly bool
+	// next[i][j] is the first hop of the one shortest i -> j path (-1 if unreachable) when
+	// computed with Options.SinglePathOnly; nil otherwise.
+	next   [][]int
+	limits *graph.Limits
+	via    viaOptions
+	// pos[i] is node i's position in Options.NodeOrder, which Results follow; nil means index order.
+	pos     []int
+	timings map[[2]string]time.Duration
+	// hopPenalty is Options.HopPenalty, already added to every edge cost of g.
+	hopPenalty int
+	// tieBreak and rng are Options.TieBreak and the Options.Shuffle source (nil if not shuffling),
+	// kept so UpdateEdge enumerates rebuilt pairs the same way.
+	tieBreak TieBreak
+	rng      *rand.Rand
+}
+
+// viaOptions are the Options that FillViaNeighborPaths applies later.
+type viaOptions struct {
+	minHops        int
+	perNeighborCap int
+	diverse        bool
+	slack          int
+	pathKey        PathKeyFunc
+}
+
+// result returns the PairResult for node indices (i, j); Results are stored in i*N+j order, with
+// i and j mapped through pos if Options.NodeOrder was given.
+func (r *AllPairsResult) result(i, j int) *PairResult {
+	if r.pos != nil {
+		i, j = r.pos[i], r.pos[j]
+	}
+	return &r.Results[i*len(r.dist)+j]
+}
+
+// Options tunes RunFloydWithOptions. A nil *Options (or the zero value) behaves like RunFloyd.
+type Options struct {
+	// GlobalPathBudget caps the total number of paths enumerated across all pairs to bound memory;
+	// 0 means unlimited. Every reachable pair gets its first path before any pair gets a second
+	// one, in Results order. Distances are always complete; only Paths is truncated.
+	GlobalPathBudget int
+	// Limits caps the paths kept per pair (MaxShortestPaths here, MaxViaNeighborPaths in
+	// FillViaNeighborPaths); nil falls back to g.Limits, then to the defaults. The cost range
+	// fields are ignored, since the graph is already built.
+	Limits *graph.Limits
+	// TieBreak orders equal-distance paths before they are capped; the zero value is AsDiscovered.
+	TieBreak TieBreak
+	// Shuffle picks among equal-distance paths that TieBreak does not order in a random but
+	// reproducible way: candidates are shuffled before capping with a generator seeded by Seed.
+	// Without it the order is deterministic.
+	Shuffle bool
+	Seed    int64
+	// Timings records the time spent enumerating each pair's paths; see EnumerationTimings.
+	Timings bool
+	// DistancesOnly computes only the distance matrix: Results carry Distance but no Paths, and
+	// the predecessor lists are built lazily if a method needs them. GlobalPathBudget, TieBreak,
+	// Shuffle and Timings are ignored.
+	DistancesOnly bool
+	// HopPenalty is added to the cost of every edge, so paths with fewer hops are preferred.
+	// Distances are then penalized costs, and each path's raw cost is reported in
+	// PathDist.Metrics[graph.CostMetric]. The result refers to a penalized copy of g, which
+	// UpdateEdge modifies instead of g.
+	HopPenalty int
+	// MinViaHops drops via-neighbor paths with fewer hops (see FillViaNeighborPaths), filtering
+	// out near-trivial detours. 0 keeps them all.
+	MinViaHops int
+	// PerNeighborCap limits how many via-neighbor paths each first-hop neighbor contributes
+	// before the MaxViaNeighborPaths cap applies, so one neighbor cannot crowd out the others.
+	// 0 means no per-neighbor limit.
+	PerNeighborCap int
+	// Diverse makes FillViaNeighborPaths favor variety over cost among near-equal candidates:
+	// every via-neighbor path within DiverseSlack of the cheapest one competes, and paths are
+	// picked greedily, preferring a first-hop neighbor not used yet, then the most nodes not on
+	// the paths picked so far, then lower distance. Remaining slots are filled by distance.
+	// DiverseSlack 0 only lets equal-cost paths compete.
+	Diverse      bool
+	DiverseSlack int
+	// PathKey keys via-neighbor paths for deduplication in FillViaNeighborPaths; nil means
+	// JoinPathKey. Set it when node names may contain "|" (see JoinPathKey) or to key very many
+	// paths more cheaply, e.g. by a hash.
+	PathKey PathKeyFunc
+	// SinglePathOnly stores one first hop per pair (N² ints) instead of the predecessor lists, and
+	// Paths holds exactly that one shortest path per pair. Methods that need predecessor lists
+	// build them on demand. GlobalPathBudget, TieBreak, Shuffle and Timings are ignored;
+	// DistancesOnly takes precedence.
+	SinglePathOnly bool
+	// NodeOrder, if set, lists every node exactly once; Results are then emitted pair by pair in
+	// that order instead of the graph's construction order. Unknown, repeated or missing names
+	// are an error.
+	NodeOrder []string
 }
 
 // RunFloyd builds distance matrix and predecessor lists from g, then enumerates up to MaxShortestPaths per pair.
+// It panics if g is inconsistent (see graph.Graph.Validate); use RunFloydWithOptions to get an error instead.
 func RunFloyd(g *graph.Graph) *AllPairsResult {
+	r, err := RunFloydWithOptions(g, nil)
+	if err != nil {
+		panic(err)
+	}
+	return r
+}
+
+// RunFloydWithOptions is RunFloyd with the behavior adjusted by opts. It validates g first and
+// returns an error instead of panicking on an inconsistent graph.
+func RunFloydWithOptions(g *graph.Graph, opts *Options) (r *AllPairsResult, err error) {
+	if opts == nil {
+		opts = &Options{}
+	}
+	if err := g.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid graph: %w", err)
+	}
+	if opts.NodeOrder != nil {
+		pos, err := nodePositions(g, opts.NodeOrder)
+		if err != nil {
+			return nil, err
+		}
+		defer func() {
+			if r != nil {
+				r.reorder(pos)
+			}
+		}()
+	}
+	if opts.HopPenalty > 0 {
+		g = withHopPenalty(g, opts.HopPenalty)
+		defer func() {
+			if r != nil {
+				r.hopPenalty = opts.HopPenalty
+				for k := range r.Results {
+					r.annotateRawCost(r.Results[k].Paths)
+				}
+			}
+		}()
+	}
+	limits := opts.Limits
+	if limits == nil {
+		limits = g.Limits
+	}
+	maxPaths := limits.Resolved().MaxShortestPaths
+	via := viaOptions{opts.MinViaHops, opts.PerNeighborCap, opts.Diverse, opts.DiverseSlack, opts.PathKey}
 	N := g.NumNodes()
-	dist := make([][]int, N)
-	for i := 0; i < N; i++ {
-		dist[i] = make([]int, N)
-		for j := 0; j < N; j++ {
-			dist[i][j] = Inf
-			if i == j {
-				dist[i][j] = 0
-			} else if w := g.Cost(i, j); w > 0 {
-				dist[i][j] = w
+	if opts.DistancesOnly {
+		dist := floydDistances(g)
+		results := make([]PairResult, 0, N*N)
+		for i := 0; i < N; i++ {
+			for j := 0; j < N; j++ {
+				results = append(results, newPairResult(g, i, j, dist[i][j], nil))
 			}
 		}
+		return &AllPairsResult{Results: results, g: g, dist: dist, limits: limits, via: via, distancesOnly: true}, nil
 	}
-	for k := 0; k < N; k++ {
+	if opts.SinglePathOnly {
+		dist, next := floydNextHops(g, Inf)
+		results := make([]PairResult, 0, N*N)
 		for i := 0; i < N; i++ {
-			if dist[i][k] == Inf {
-				continue
-			}
 			for j := 0; j < N; j++ {
-				if dist[k][j] == Inf {
-					continue
-				}
-				d := dist[i][k] + dist[k][j]
-				if d < dist[i][j] {
-					dist[i][j] = d
+				var paths []PathDist
+				if dist[i][j] != Inf {
+					paths = []PathDist{{Path: nextHopPath(g, next, i, j), Distance: dist[i][j]}}
 				}
+				results = append(results, newPairResult(g, i, j, dist[i][j], paths))
 			}
 		}
+		return &AllPairsResult{Results: results, g: g, dist: dist, next: next, limits: limits, via: via}, nil
 	}
-	// Predecessors: pred[i][j] = list of m (m != i) such that edge (m,j) exists and dist[i][m]+w(m,j)==dist[i][j]
-	// Exclude m==i to avoid cycles (i->i->j).
-	pred := make([][][]int, N)
-	for i := 0; i < N; i++ {
-		pred[i] = make([][]int, N)
-		for j := 0; j < N; j++ {
-			if i == j || dist[i][j] == Inf {
-				continue
+	dist, pred := floydWarshall(g)
+	// Paths are enumerated with KShortestSimplePaths, so besides the shortest ones they may
+	// include 2nd, 3rd, ... shortest alternatives; pred only describes the shortest-path DAG.
+	paths := make([][]PathDist, N*N)
+	var timings map[[2]string]time.Duration
+	if opts.Timings {
+		timings = make(map[[2]string]time.Duration)
+	}
+	var rng *rand.Rand
+	if opts.Shuffle {
+		rng = rand.New(rand.NewSource(opts.Seed))
+	}
+	enumerate := func(i, j, k int) []PathDist {
+		if timings == nil {
+			return kShortestTieBroken(g, i, j, k, opts.TieBreak, rng)
+		}
+		start := time.Now()
+		p := kShortestTieBroken(g, i, j, k, opts.TieBreak, rng)
+		timings[[2]string{g.Name(i), g.Name(j)}] += time.Since(start)
+		return p
+	}
+	if budget := opts.GlobalPathBudget; budget > 0 {
+		// First pass: one path per reachable pair; second pass: top up while budget remains.
+		for i := 0; i < N && budget > 0; i++ {
+			for j := 0; j < N && budget > 0; j++ {
+				if dist[i][j] != Inf {
+					paths[i*N+j] = enumerate(i, j, 1)
+					budget -= len(paths[i*N+j])
+				}
 			}
-			for m := 0; m < N; m++ {
-				if m == i {
-					continue
+		}
+		for i := 0; i < N && budget > 0; i++ {
+			for j := 0; j < N && budget > 0; j++ {
+				if have := len(paths[i*N+j]); have == 1 && maxPaths > 1 {
+					more := enumerate(i, j, min(maxPaths, have+budget))
+					budget -= len(more) - have
+					paths[i*N+j] = more
 				}
-				w := g.Cost(m, j)
-				if w > 0 && dist[i][m] != Inf && dist[i][m]+w == dist[i][j] {
-					pred[i][j] = append(pred[i][j], m)
+			}
+		}
+	} else {
+		for i := 0; i < N; i++ {
+			for j := 0; j < N; j++ {
+				if dist[i][j] != Inf {
+					paths[i*N+j] = enumerate(i, j, maxPaths)
 				}
 			}
 		}
 	}
-	// Build path list by backtracking: for i->j, paths go i -> ... -> m -> j for m in pred[i][j]
-	// We need to enumerate paths. Use recursion: path from i to j = for each k in pred[i][j],
-	// path(i,k) + path(k,j) with k not repeated in the middle. Actually pred[i][j] are predecessors of j,
-	// so edge (k,j) is on shortest path. So dist[i][k] + w(k,j) = dist[i][j]. So path = path(i,k) + [j].
-	// Recursively path(i,k) = for each pred of k, path(i, pred) + [k]. We need to avoid cycles; with
-	// positive weights shortest paths are acyclic. So we can recursively enumerate and cap at 4.
 	results := make([]PairResult, 0, N*N)
 	for i := 0; i < N; i++ {
 		for j := 0; j < N; j++ {
-			pr := PairResult{
-				From:     g.Name(i),
-				To:       g.Name(j),
-				Distance: dist[i][j],
-				Paths:    nil,
+			results = append(results, newPairResult(g, i, j, dist[i][j], paths[i*N+j]))
+		}
+	}
+	return &AllPairsResult{Results: results, g: g, dist: dist, pred: pred, limits: limits, via: via, timings: timings,
+		tieBreak: opts.TieBreak, rng: rng}, nil
+}
+
+// nodePositions maps each node index of g to its position in order, which must list every node
+// of g exactly once.
+func nodePositions(g *graph.Graph, order []string) ([]int, error) {
+	N := g.NumNodes()
+	if len(order) != N {
+		return nil, fmt.Errorf("node order lists %d nodes, graph has %d", len(order), N)
+	}
+	pos := make([]int, N)
+	for i := range pos {
+		pos[i] = -1
+	}
+	for k, name := range order {
+		i, ok := g.Index(name)
+		if !ok {
+			return nil, fmt.Errorf("node order: unknown node %q", name)
+		}
+		if pos[i] >= 0 {
+			return nil, fmt.Errorf("node order: %q listed twice", name)
+		}
+		pos[i] = k
+	}
+	return pos, nil
+}
+
+// reorder permutes Results so that pair (i, j) moves to pos[i]*N+pos[j].
+func (r *AllPairsResult) reorder(pos []int) {
+	N := len(r.dist)
+	results := make([]PairResult, len(r.Results))
+	for i := 0; i < N; i++ {
+		for j := 0; j < N; j++ {
+			results[pos[i]*N+pos[j]] = r.Results[i*N+j]
+		}
+	}
+	r.Results, r.pos = results, pos
+}
+
+// DistanceMatrix returns a copy of the shortest distances, indexed like the graph's nodes, with
+// Unreachable for pairs without a path.
+func (r *AllPairsResult) DistanceMatrix() [][]int {
+	m := make([][]int, len(r.dist))
+	for i, row := range r.dist {
+		m[i] = make([]int, len(row))
+		for j, d := range row {
+			if d == Inf {
+				d = Unreachable
 			}
-			if dist[i][j] != Inf {
-				pr.Paths = KShortestSimplePaths(g, i, j, MaxShortestPaths)
-				if len(pr.Paths) > 0 {
-					pr.Distance = pr.Paths[0].Distance
-				}
+			m[i][j] = d
+		}
+	}
+	return m
+}
+
+// NormalizedDistanceMatrix returns the distances scaled to [0, 1] for heatmaps, together with the
+// node names indexing both dimensions. Distances are divided by the largest finite distance of
+// any pair (the diameter when every pair is reachable), so that pair maps to 1.0. Self pairs are
+// 0.0 and unreachable pairs are NaN, so they stand out from the longest routes.
+func (r *AllPairsResult) NormalizedDistanceMatrix() ([][]float64, []string) {
+	longest := 0
+	for _, row := range r.dist {
+		for _, d := range row {
+			if d != Inf {
+				longest = max(longest, d)
 			}
-			if pr.Distance == Inf {
-				pr.Distance = -1
+		}
+	}
+	m := make([][]float64, len(r.dist))
+	names := make([]string, len(r.dist))
+	for i, row := range r.dist {
+		names[i] = r.g.Name(i)
+		m[i] = make([]float64, len(row))
+		for j, d := range row {
+			switch {
+			case d == Inf:
+				m[i][j] = math.NaN()
+			case d > 0:
+				m[i][j] = float64(d) / float64(longest)
 			}
-			results = append(results, pr)
 		}
 	}
-	return &AllPairsResult{Results: results, g: g, dist: dist, pred: pred}
+	return m, names
+}
+
+// EnumerationTimings returns the time spent enumerating the paths of each reachable pair, keyed
+// by {from, to}, if the result was computed with Options.Timings; otherwise nil.
+func (r *AllPairsResult) EnumerationTimings() map[[2]string]time.Duration {
+	return r.timings
+}
+
+// RunFloydWithWeightFunc computes all pairs where paths rooted at source src use the effective
+// cost wf(src, i, j, base) for each existing edge i->j of cost base; a result <= 0 removes the
+// edge for that source. It runs one single-source search per source, so dist[src] and pred[src]
+// reflect src's view of the weights. A wf returning base reproduces RunFloyd.
+func RunFloydWithWeightFunc(g *graph.Graph, wf func(src, i, j, base int) int) *AllPairsResult {
+	maxPaths := g.Limits.Resolved().MaxShortestPaths
+	skip := nonTransitMask(g)
+	nw := nodeWeightVector(g)
+	N := g.NumNodes()
+	dist := make([][]int, N)
+	pred := make([][][]int, N)
+	results := make([]PairResult, 0, N*N)
+	for src := 0; src < N; src++ {
+		cost := func(i, j int) int {
+			base := g.Cost(i, j)
+			if base == 0 {
+				return 0
+			}
+			return max(wf(src, i, j, base), 0)
+		}
+		dist[src] = dijkstra(N, src, withNodeWeights(cost, src, nw), skip)
+		pred[src] = predRow(N, src, dist[src], cost, skip, nw)
+		for j := 0; j < N; j++ {
+			var paths []PathDist
+			if dist[src][j] != Inf {
+				paths = kShortestSimplePaths(g, src, j, maxPaths, cost)
+			}
+			results = append(results, newPairResult(g, src, j, dist[src][j], paths))
+		}
+	}
+	return &AllPairsResult{Results: results, g: g, dist: dist, pred: pred, limits: g.Limits}
+}
+
+// newPairResult builds the PairResult for (i, j); d == Inf is reported as Unreachable.
+func newPairResult(g *graph.Graph, i, j int, d int, paths []PathDist) PairResult {
+	pr := PairResult{
+		From:     g.Name(i),
+		To:       g.Name(j),
+		Distance: d,
+		Paths:    paths,
+	}
+	if len(paths) > 0 {
+		pr.Distance = paths[0].Distance
+	}
+	if pr.Distance == Inf {
+		pr.Distance = Unreachable
+	}
+	return pr
 }
 
 // enumeratePaths returns up to maxPaths shortest paths from i to j using pred.
@@ -125,57 +434,73 @@ func enumeratePaths(g *graph.Graph, dist [][]int, pred [][][]int, i, j int, maxP
 	if i == j {
 		return [][]string{{g.Name(i)}}
 	}
-	if dist[i][j] == Inf {
+	if dist[i][j] == Inf || maxPaths <= 0 {
 		return nil
 	}
 	var out [][]string
-	seen := make(map[string]bool)
-	collectPaths(g, dist, pred, i, j, []string{g.Name(j)}, &out, seen, maxPaths)
+	walkPredPaths(g, dist, pred, i, j, []string{g.Name(j)}, make(map[string]bool), func(path []string) bool {
+		out = append(out, path)
+		return len(out) < maxPaths
+	})
 	return out
 }
 
-func collectPaths(g *graph.Graph, dist [][]int, pred [][][]int, i, j int, suffix []string, out *[][]string, seen map[string]bool, maxPaths int) {
-	if len(*out) >= maxPaths {
-		return
-	}
-	if i == j {
+// walkPredPaths calls fn for each distinct shortest path from i to the end of suffix, following
+// pred backwards from j; it returns false as soon as fn does, stopping the walk.
+func walkPredPaths(g *graph.Graph, dist [][]int, pred [][][]int, i, j int, suffix []string, seen map[string]bool, fn func([]string) bool) bool {
+	emit := func() bool {
 		path := make([]string, 0, len(suffix)+1)
 		path = append(path, g.Name(i))
 		path = append(path, suffix...)
-		key := pathKey(path)
-		if !seen[key] {
-			seen[key] = true
-			*out = append(*out, path)
+		key := JoinPathKey(path)
+		if seen[key] {
+			return true
 		}
-		return
+		seen[key] = true
+		return fn(path)
+	}
+	if i == j {
+		return emit()
 	}
 	// Direct edge (i,j): add path [i,j,...] if it is a shortest path (avoids cycle from pred with m==i).
 	if w := g.Cost(i, j); w > 0 && w == dist[i][j] {
-		path := make([]string, 0, len(suffix)+1)
-		path = append(path, g.Name(i))
-		path = append(path, suffix...)
-		key := pathKey(path)
-		if !seen[key] {
-			seen[key] = true
-			*out = append(*out, path)
+		if !emit() {
+			return false
 		}
 	}
 	for _, m := range pred[i][j] {
 		// path i->j = path(i,m) + [j]; recurse with tail [m,...,j] so output is [i,...,m,...,j]
 		tail := append([]string{g.Name(m)}, suffix...)
-		collectPaths(g, dist, pred, i, m, tail, out, seen, maxPaths)
+		if !walkPredPaths(g, dist, pred, i, m, tail, seen, fn) {
+			return false
+		}
 	}
+	return true
 }
 
-func pathKey(path []string) string {
-	s := ""
+// PathKeyFunc returns a key identifying path for deduplication (see Options.PathKey). Equal
+// paths must get equal keys and different paths different keys.
+type PathKeyFunc func(path []string) string
+
+// JoinPathKey returns the node names joined by "|", built in a single pre-sized allocation. Keys
+// are only unique if no name contains "|": ["a|b", "c"] and ["a", "b|c"] both give "a|b|c".
+func JoinPathKey(path []string) string {
+	if len(path) == 0 {
+		return ""
+	}
+	n := len(path) - 1
 	for _, p := range path {
-		if s != "" {
-			s += "|"
+		n += len(p)
+	}
+	var b strings.Builder
+	b.Grow(n)
+	for i, p := range path {
+		if i > 0 {
+			b.WriteByte('|')
 		}
-		s += p
+		b.WriteString(p)
 	}
-	return s
+	return b.String()
 }
 
 // pathState is a (distance, path) for the k-shortest heap. Path is node indices.
@@ -184,13 +509,18 @@ type pathState struct {
 	path []int
 }
 
-// pathHeap is a min-heap by distance.
+// pathHeap is a min-heap by distance, ties broken by lessIndexPath so the pop order is fixed.
 type pathHeap []pathState
 
-func (h pathHeap) Len() int           { return len(h) }
-func (h pathHeap) Less(i, j int) bool { return h[i].dist < h[j].dist }
-func (h pathHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
-func (h *pathHeap) Push(x any)        { *h = append(*h, x.(pathState)) }
+func (h pathHeap) Len() int { return len(h) }
+func (h pathHeap) Less(i, j int) bool {
+	if h[i].dist != h[j].dist {
+		return h[i].dist < h[j].dist
+	}
+	return lessIndexPath(h[i].path, h[j].path)
+}
+func (h pathHeap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+func (h *pathHeap) Push(x any)   { *h = append(*h, x.(pathState)) }
 func (h *pathHeap) Pop() any {
 	old := *h
 	n := len(old)
@@ -198,6 +528,16 @@ func (h *pathHeap) Pop() any {
 	return old[n-1]
 }
 
+// lessIndexPath compares two paths of node indices lexicographically; a proper prefix is smaller.
+func lessIndexPath(a, b []int) bool {
+	for k := 0; k < len(a) && k < len(b); k++ {
+		if a[k] != b[k] {
+			return a[k] < b[k]
+		}
+	}
+	return len(a) < len(b)
+}
+
 func pathContains(path []int, x int) bool {
 	for _, v := range path {
 		if v == x {
@@ -210,15 +550,38 @@ func pathContains(path []int, x int) bool {
 // KShortestSimplePaths returns up to k simple paths from fromIdx to toIdx, sorted by total distance.
 // Paths may have different distances (1st shortest, 2nd shortest, ...).
 func KShortestSimplePaths(g *graph.Graph, fromIdx, toIdx int, k int) []PathDist {
+	return kShortestSimplePaths(g, fromIdx, toIdx, k, g.Cost)
+}
+
+// kShortestSimplePaths is KShortestSimplePaths with edge costs taken from cost (0 = no edge).
+func kShortestSimplePaths(g *graph.Graph, fromIdx, toIdx int, k int, cost func(i, j int) int) []PathDist {
+	if k <= 0 {
+		return nil
+	}
+	var results []PathDist
+	walkSimplePaths(g, fromIdx, toIdx, cost, func(p PathDist) bool {
+		results = append(results, p)
+		return len(results) < k
+	})
+	return results
+}
+
+// walkSimplePaths calls fn for each simple path from fromIdx to toIdx in non-decreasing order of
+// distance (best-first search over a min-heap of partial paths) until fn returns false or the
+// paths are exhausted.
+func walkSimplePaths(g *graph.Graph, fromIdx, toIdx int, cost func(i, j int) int, fn func(PathDist) bool) {
 	if fromIdx == toIdx {
-		return []PathDist{{Path: []string{g.Name(fromIdx)}, Distance: 0}}
+		fn(PathDist{Path: []string{g.Name(fromIdx)}, Distance: 0})
+		return
 	}
+	N := g.NumNodes()
+	skip := nonTransitMask(g)
+	cost = withNodeWeights(cost, fromIdx, nodeWeightVector(g))
 	h := &pathHeap{}
 	heap.Init(h)
 	heap.Push(h, pathState{0, []int{fromIdx}})
-	var results []PathDist
 	seen := make(map[string]bool)
-	for h.Len() > 0 && len(results) < k {
+	for h.Len() > 0 {
 		s := heap.Pop(h).(pathState)
 		last := s.path[len(s.path)-1]
 		if last == toIdx {
@@ -226,46 +589,55 @@ func KShortestSimplePaths(g *graph.Graph, fromIdx, toIdx int, k int) []PathDist
 			for i, idx := range s.path {
 				names[i] = g.Name(idx)
 			}
-			key := pathKey(names)
+			key := JoinPathKey(names)
 			if seen[key] {
 				continue
 			}
 			seen[key] = true
-			results = append(results, PathDist{Path: names, Distance: s.dist})
+			if !fn(PathDist{Path: names, Distance: s.dist}) {
+				return
+			}
+			continue
+		}
+		if last != fromIdx && skip != nil && skip[last] {
 			continue
 		}
-		for _, nb := range g.Neighbors(last) {
-			if pathContains(s.path, nb) {
+		for nb := 0; nb < N; nb++ {
+			w := cost(last, nb)
+			if w <= 0 || pathContains(s.path, nb) {
 				continue
 			}
-			w := g.Cost(last, nb)
 			newPath := make([]int, len(s.path)+1)
 			copy(newPath, s.path)
 			newPath[len(newPath)-1] = nb
 			heap.Push(h, pathState{s.dist + w, newPath})
 		}
 	}
-	return results
 }
 
-// FillViaNeighborPaths computes for each pair (S,D) up to MaxViaNeighborPaths paths of the form
-// S -> N -> ... -> D where N is an out-neighbor of S and the path N->...->D does not contain S.
+// FillViaNeighborPaths computes for each pair (S,D) up to MaxViaNeighborPaths paths (or the
+// limit the result was computed with) of the form S -> N -> ... -> D where N is an out-neighbor
+// of S and the path N->...->D does not contain S. When N is D itself the one-hop path S -> D is
+// included, even if D is a non-transit node. Paths with fewer than Options.MinViaHops hops
+// are left out, and each neighbor contributes at most Options.PerNeighborCap paths if set. With
+// Options.Diverse the paths are picked for variety among near-equal costs (see Options).
 func (r *AllPairsResult) FillViaNeighborPaths() {
 	g := r.g
+	maxVia := r.limits.Resolved().MaxViaNeighborPaths
 	N := g.NumNodes()
+	nw := nodeWeightVector(g)
 	for fromIdx := 0; fromIdx < N; fromIdx++ {
 		neighbors := g.Neighbors(fromIdx)
 		if len(neighbors) == 0 {
 			continue
 		}
 		sub, oldToNew := g.CopyWithoutNode(fromIdx)
-		subDist, subPred := runFloydOnSubgraph(sub)
+		subDist, subPred := floydWarshall(sub)
 		fromName := g.Name(fromIdx)
 		for toIdx := 0; toIdx < N; toIdx++ {
 			if toIdx == fromIdx {
 				continue
 			}
-			toName := g.Name(toIdx)
 			newTo := oldToNew[toIdx]
 			if newTo < 0 {
 				continue
@@ -274,120 +646,281 @@ func (r *AllPairsResult) FillViaNeighborPaths() {
 			for _, nb := range neighbors {
 				wSN := g.Cost(fromIdx, nb)
 				newNb := oldToNew[nb]
-				if newNb < 0 {
+				if newNb < 0 || nb != toIdx && !g.Transit(nb) {
 					continue
 				}
 				if subDist[newNb][newTo] == Inf {
 					continue
 				}
 				d := wSN + subDist[newNb][newTo]
-				paths := enumeratePathsOnSub(sub, subDist, subPred, newNb, newTo, MaxViaNeighborPaths)
+				if nw != nil && nb != toIdx {
+					d += nw[nb]
+				}
+				// len(p) is the hop count once fromName is prepended.
+				paths := enumeratePathsOnSub(sub, subDist, subPred, newNb, newTo, maxVia, r.via.minHops)
+				taken := 0
 				for _, p := range paths {
+					if r.via.perNeighborCap > 0 && taken == r.via.perNeighborCap {
+						break
+					}
+					taken++
 					fullPath := append([]string{fromName}, p...)
 					candidates = append(candidates, PathDist{Path: fullPath, Distance: d})
 				}
 			}
-			// Sort by distance and take up to MaxViaNeighborPaths unique paths (by path key)
-			dedup := dedupPathsByKey(candidates, MaxViaNeighborPaths)
-			// Find the PairResult for (fromName, toName)
-			for i := range r.Results {
-				if r.Results[i].From == fromName && r.Results[i].To == toName {
-					r.Results[i].ViaNeighborPaths = dedup
-					break
-				}
+			// Sort by distance and take up to maxVia unique paths (by path key)
+			var dedup []PathDist
+			if r.via.diverse {
+				dedup = selectDiverse(dedupPathsByKey(candidates, len(candidates), r.via.pathKey), maxVia, r.via.slack)
+			} else {
+				dedup = dedupPathsByKey(candidates, maxVia, r.via.pathKey)
 			}
+			r.result(fromIdx, toIdx).ViaNeighborPaths = dedup
 		}
 	}
 }
 
-func runFloydOnSubgraph(g *graph.Graph) (dist [][]int, pred [][][]int) {
-	n := g.NumNodes()
-	dist = make([][]int, n)
-	for i := 0; i < n; i++ {
-		dist[i] = make([]int, n)
-		for j := 0; j < n; j++ {
-			dist[i][j] = Inf
-			if i == j {
-				dist[i][j] = 0
-			} else if w := g.Cost(i, j); w > 0 {
-				dist[i][j] = w
-			}
-		}
+// floydWarshall returns the all-pairs distance matrix of g (Inf for unreachable) and
+// the predecessor lists built from it. Non-transit nodes are never intermediate hops.
+func floydWarshall(g *graph.Graph) (dist [][]int, pred [][][]int) {
+	dist = floydDistances(g)
+	return dist, predMatrix(g, dist)
+}
+
+// floydDistances returns the all-pairs distance matrix of g (Inf for unreachable).
+func floydDistances(g *graph.Graph) [][]int {
+	adj := g.CostMatrix()
+	return floydCore(len(adj), func(i, j int) int { return adj[i][j] }, Inf, nil, nonTransitMask(g), nodeWeightVector(g))
+}
+
+// floydNextHops returns the all-pairs distance matrix of g (inf for unreachable) and the first
+// hop of one shortest path per pair (-1 if unreachable).
+func floydNextHops[W graph.Weight](g *graph.Weighted[W], inf W) (dist [][]W, next [][]int) {
+	adj := g.CostMatrix()
+	next = make([][]int, len(adj))
+	for i := range next {
+		next[i] = make([]int, len(adj))
 	}
-	for k := 0; k < n; k++ {
-		for i := 0; i < n; i++ {
-			if dist[i][k] == Inf {
-				continue
-			}
-			for j := 0; j < n; j++ {
-				if dist[k][j] == Inf {
-					continue
-				}
-				d := dist[i][k] + dist[k][j]
-				if d < dist[i][j] {
-					dist[i][j] = d
-				}
-			}
-		}
+	dist = floydCore(len(adj), func(i, j int) W { return adj[i][j] }, inf, next, nonTransitMask(g), nodeWeightVector(g))
+	return dist, next
+}
+
+// nextHopPath follows next from i to j, which must be reachable.
+func nextHopPath(g *graph.Graph, next [][]int, i, j int) []string {
+	path := []string{g.Name(i)}
+	for u := i; u != j; {
+		u = next[u][j]
+		path = append(path, g.Name(u))
 	}
-	pred = make([][][]int, n)
+	return path
+}
+
+// predMatrix returns the predecessor lists of every source given g's distance matrix dist.
+func predMatrix(g *graph.Graph, dist [][]int) [][][]int {
+	adj := g.CostMatrix()
+	cost := func(i, j int) int { return adj[i][j] }
+	n := len(adj)
+	skip := nonTransitMask(g)
+	nw := nodeWeightVector(g)
+	pred := make([][][]int, n)
 	for i := 0; i < n; i++ {
-		pred[i] = make([][]int, n)
-		for j := 0; j < n; j++ {
-			if i == j || dist[i][j] == Inf {
+		pred[i] = predRow(n, i, dist[i], cost, skip, nw)
+	}
+	return pred
+}
+
+// predecessors returns r.pred, building it from r.dist first if the result was computed with
+// Options.DistancesOnly.
+func (r *AllPairsResult) predecessors() [][][]int {
+	r.predOnce.Do(func() {
+		if r.pred == nil {
+			r.pred = predMatrix(r.g, r.dist)
+		}
+	})
+	return r.pred
+}
+
+// predRow returns the predecessor lists for source i given its distance row:
+// row[j] = list of m (m != i) such that edge (m,j) exists (cost != 0) and dist[m]+w(m,j)==dist[j].
+// m==i is excluded to avoid cycles (i->i->j); the direct edge is handled by the enumerators.
+// Nodes with skip[m] set (non-transit, may be nil) are excluded as well. If nw is non-nil, m is
+// an intermediate node and its weight nw[m] is part of dist[j].
+func predRow(n, i int, dist []int, cost func(m, j int) int, skip []bool, nw []int) [][]int {
+	row := make([][]int, n)
+	for j := 0; j < n; j++ {
+		if i == j || dist[j] == Inf {
+			continue
+		}
+		for m := 0; m < n; m++ {
+			if m == i || skip != nil && skip[m] {
 				continue
 			}
-			for m := 0; m < n; m++ {
-				if m == i {
-					continue
-				}
-				w := g.Cost(m, j)
-				if w > 0 && dist[i][m] != Inf && dist[i][m]+w == dist[i][j] {
-					pred[i][j] = append(pred[i][j], m)
-				}
+			w := cost(m, j)
+			if w != 0 && nw != nil {
+				w += nw[m]
+			}
+			if w != 0 && dist[m] != Inf && dist[m]+w == dist[j] {
+				row[j] = append(row[j], m)
 			}
 		}
 	}
-	return dist, pred
+	return row
 }
 
-func enumeratePathsOnSub(g *graph.Graph, dist [][]int, pred [][][]int, i, j int, maxPaths int) [][]string {
-	if i == j {
-		return [][]string{{g.Name(i)}}
+// enumeratePathsOnSub is enumeratePaths keeping only paths of at least minLen nodes, so that
+// shorter ones do not use up maxPaths. At most MaxSimplePaths paths are considered.
+func enumeratePathsOnSub(g *graph.Graph, dist [][]int, pred [][][]int, i, j int, maxPaths, minLen int) [][]string {
+	if minLen <= 1 {
+		return enumeratePaths(g, dist, pred, i, j, maxPaths)
 	}
-	if dist[i][j] == Inf {
+	if i == j || dist[i][j] == Inf || maxPaths <= 0 {
 		return nil
 	}
 	var out [][]string
-	seen := make(map[string]bool)
-	collectPaths(g, dist, pred, i, j, []string{g.Name(j)}, &out, seen, maxPaths)
+	considered := 0
+	walkPredPaths(g, dist, pred, i, j, []string{g.Name(j)}, make(map[string]bool), func(path []string) bool {
+		if len(path) >= minLen {
+			out = append(out, path)
+		}
+		considered++
+		return len(out) < maxPaths && considered < MaxSimplePaths
+	})
 	return out
 }
 
-// dedupPathsByKey sorts by distance and returns up to max paths, deduplicated by path key.
-func dedupPathsByKey(candidates []PathDist, max int) []PathDist {
-	if len(candidates) == 0 {
+// nonTransitMask returns skip with skip[k] set for every non-transit node of g, or nil if all
+// nodes are transit nodes.
+func nonTransitMask[W graph.Weight](g *graph.Weighted[W]) []bool {
+	if len(g.NonTransit) == 0 {
+		return nil
+	}
+	skip := make([]bool, g.NumNodes())
+	for k := range skip {
+		skip[k] = !g.Transit(k)
+	}
+	return skip
+}
+
+// nodeWeightVector returns g's node weights indexed like its nodes, or nil if it has none.
+func nodeWeightVector[W graph.Weight](g *graph.Weighted[W]) []W {
+	if len(g.NodeWeights) == 0 {
 		return nil
 	}
-	// simple sort by distance
-	for i := 0; i < len(candidates); i++ {
-		for j := i + 1; j < len(candidates); j++ {
-			if candidates[j].Distance < candidates[i].Distance {
-				candidates[i], candidates[j] = candidates[j], candidates[i]
+	nw := make([]W, g.NumNodes())
+	for i := range nw {
+		nw[i] = g.NodeWeight(i)
+	}
+	return nw
+}
+
+// withNodeWeights returns cost with the weight of i added to every edge i->j leaving a node i
+// other than src, so that paths from src pay for each intermediate node they pass through.
+// It returns cost itself if nw is nil.
+func withNodeWeights(cost func(i, j int) int, src int, nw []int) func(i, j int) int {
+	if nw == nil {
+		return cost
+	}
+	return func(i, j int) int {
+		w := cost(i, j)
+		if w != 0 && i != src {
+			w += nw[i]
+		}
+		return w
+	}
+}
+
+// withHopPenalty returns a copy of g with penalty added to every edge cost. The copy's cost range
+// is shifted by penalty as well, so AddEdge on it accepts exactly the penalized costs of edges
+// that g would accept.
+func withHopPenalty(g *graph.Graph, penalty int) *graph.Graph {
+	c := g.Clone()
+	lim := g.Limits.Resolved()
+	lim.MinCost += penalty
+	lim.MaxCost += penalty
+	c.Limits = &lim
+	for _, row := range c.AdjMatrix {
+		for j, w := range row {
+			if w != 0 {
+				row[j] = w + penalty
 			}
 		}
 	}
+	return c
+}
+
+// selectDiverse picks up to max paths from sorted (by distance, unique) as described for
+// Options.Diverse, returning them ordered by distance.
+func selectDiverse(sorted []PathDist, max, slack int) []PathDist {
+	if len(sorted) <= max {
+		return sorted
+	}
+	limit := sorted[0].Distance + slack
+	picked := make([]bool, len(sorted))
+	usedHop := make(map[string]bool)
+	usedNode := make(map[string]bool)
+	var out []PathDist
+	for len(out) < max {
+		best, bestNewHop, bestNew := -1, false, -1
+		for k, p := range sorted {
+			if picked[k] || p.Distance > limit {
+				continue
+			}
+			newHop := !usedHop[p.Path[1]]
+			fresh := 0
+			for _, n := range p.Path[1:] {
+				if !usedNode[n] {
+					fresh++
+				}
+			}
+			if best < 0 || newHop && !bestNewHop || newHop == bestNewHop && fresh > bestNew {
+				best, bestNewHop, bestNew = k, newHop, fresh
+			}
+		}
+		if best < 0 {
+			break
+		}
+		picked[best] = true
+		out = append(out, sorted[best])
+		usedHop[sorted[best].Path[1]] = true
+		for _, n := range sorted[best].Path[1:] {
+			usedNode[n] = true
+		}
+	}
+	for k, p := range sorted {
+		if len(out) >= max {
+			break
+		}
+		if !picked[k] {
+			out = append(out, p)
+		}
+	}
+	sort.SliceStable(out, func(a, b int) bool { return out[a].Distance < out[b].Distance })
+	return out
+}
+
+// dedupPathsByKey stably sorts by distance (equal distances keep their candidate order) and
+// returns up to max paths, deduplicated by key (JoinPathKey if nil).
+func dedupPathsByKey(candidates []PathDist, max int, key PathKeyFunc) []PathDist {
+	if len(candidates) == 0 {
+		return nil
+	}
+	if key == nil {
+		key = JoinPathKey
+	}
+	sort.SliceStable(candidates, func(a, b int) bool {
+		return candidates[a].Distance < candidates[b].Distance
+	})
 	var result []PathDist
 	seen := make(map[string]bool)
 	for _, c := range candidates {
 		if len(result) >= max {
 			break
 		}
-		key := pathKey(c.Path)
-		if seen[key] {
+		k := key(c.Path)
+		if seen[k] {
 			continue
 		}
-		seen[key] = true
+		seen[k] = true
 		result = append(result, c)
 	}
 	return result
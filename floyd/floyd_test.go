@@ -1,6 +1,10 @@
 package floyd
 
 import (
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/jursonmo/pathroute/graph"
@@ -163,3 +167,810 @@ func TestViaNeighbor_StartHasNoOutEdges(t *testing.T) {
 		t.Errorf("A has no out-neighbors, via-neighbor paths should be empty: %v", ab.ViaNeighborPaths)
 	}
 }
+
+func TestViaNeighbor_DestinationIsNeighbor(t *testing.T) {
+	g, _ := graph.NewFromStruct(&graph.GraphJSON{
+		Edges: []graph.Edge{
+			{From: "A", To: "D", Cost: 30},
+			{From: "A", To: "B", Cost: 10},
+			{From: "B", To: "D", Cost: 10},
+			{From: "C", To: "D", Cost: 10},
+		},
+		NonTransit: []string{"D"},
+	})
+	r := RunFloyd(g)
+	r.FillViaNeighborPaths()
+	var got []string
+	for _, p := range findResult(r, "A", "D").ViaNeighborPaths {
+		got = append(got, fmt.Sprintf("%s=%d", JoinPathKey(p.Path), p.Distance))
+	}
+	if want := []string{"A|B|D=20", "A|D=30"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("A->D via-neighbor paths = %v, want %v", got, want)
+	}
+	// D is C's only neighbor, so the direct edge is its only via path.
+	if via := findResult(r, "C", "D").ViaNeighborPaths; len(via) != 1 || JoinPathKey(via[0].Path) != "C|D" {
+		t.Errorf("C->D via-neighbor paths = %v, want [C D]", via)
+	}
+}
+
+func TestViaNeighbor_MinViaHops(t *testing.T) {
+	g, _ := graph.NewFromStruct(&graph.GraphJSON{
+		Edges: []graph.Edge{
+			{From: "A", To: "D", Cost: 10},
+			{From: "A", To: "B", Cost: 10},
+			{From: "B", To: "D", Cost: 10},
+			{From: "A", To: "C", Cost: 10},
+			{From: "C", To: "E", Cost: 10},
+			{From: "E", To: "D", Cost: 10},
+		},
+	})
+	for _, tc := range []struct {
+		minHops int
+		want    []string
+	}{
+		{0, []string{"A|D", "A|B|D", "A|C|E|D"}},
+		{3, []string{"A|C|E|D"}},
+	} {
+		r, err := RunFloydWithOptions(g, &Options{MinViaHops: tc.minHops})
+		if err != nil {
+			t.Fatal(err)
+		}
+		r.FillViaNeighborPaths()
+		var got []string
+		for _, p := range findResult(r, "A", "D").ViaNeighborPaths {
+			got = append(got, JoinPathKey(p.Path))
+		}
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("MinViaHops=%d: got %v, want %v", tc.minHops, got, tc.want)
+		}
+	}
+}
+
+func TestViaNeighbor_MinViaHopsBeforeCap(t *testing.T) {
+	// N has four equal-cost paths to D; the only one with enough hops is enumerated last.
+	g, _ := graph.NewFromStruct(&graph.GraphJSON{
+		Edges: []graph.Edge{
+			{From: "A", To: "N", Cost: 1},
+			{From: "N", To: "D", Cost: 30},
+			{From: "N", To: "X1", Cost: 15}, {From: "X1", To: "D", Cost: 15},
+			{From: "N", To: "X2", Cost: 15}, {From: "X2", To: "D", Cost: 15},
+			{From: "N", To: "Y", Cost: 10}, {From: "Y", To: "Z", Cost: 10}, {From: "Z", To: "D", Cost: 10},
+		},
+	})
+	r, err := RunFloydWithOptions(g, &Options{MinViaHops: 4, Limits: &graph.Limits{MaxViaNeighborPaths: 2}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.FillViaNeighborPaths()
+	via := findResult(r, "A", "D").ViaNeighborPaths
+	if len(via) != 1 || JoinPathKey(via[0].Path) != "A|N|Y|Z|D" {
+		t.Errorf("A->D via-neighbor paths = %v, want [A N Y Z D]", via)
+	}
+}
+
+func TestRunFloyd_UndirectedOneWay(t *testing.T) {
+	g, err := graph.NewFromStructWithOptions(&graph.GraphJSON{Edges: []graph.Edge{
+		{From: "A", To: "B", Cost: 10},
+		{From: "B", To: "C", Cost: 10, OneWay: true},
+	}}, &graph.Options{Undirected: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := RunFloyd(g)
+	for _, tc := range []struct {
+		from, to string
+		want     int
+	}{
+		{"A", "C", 20}, {"B", "A", 10}, {"C", "B", Unreachable}, {"C", "A", Unreachable},
+	} {
+		if got := findResult(r, tc.from, tc.to).Distance; got != tc.want {
+			t.Errorf("%s -> %s: distance %d, want %d", tc.from, tc.to, got, tc.want)
+		}
+	}
+}
+
+func TestRunFloyd_WeightScaleDoublesDistances(t *testing.T) {
+	gj := weightedGraph(t).ToGraphJSON()
+	scaled, err := graph.NewFromStructWithOptions(gj, &graph.Options{WeightScale: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	base, doubled := RunFloyd(weightedGraph(t)), RunFloyd(scaled)
+	for k, pr := range base.Results {
+		want := pr.Distance
+		if IsReachable(want) {
+			want *= 2
+		}
+		if got := doubled.Results[k].Distance; got != want {
+			t.Errorf("%s -> %s: got %d, want %d", pr.From, pr.To, got, want)
+		}
+	}
+}
+
+func TestRunFloyd_NodeWeightReroutes(t *testing.T) {
+	gj := &graph.GraphJSON{
+		Edges: []graph.Edge{
+			{From: "A", To: "B", Cost: 10},
+			{From: "B", To: "D", Cost: 10},
+			{From: "A", To: "C", Cost: 15},
+			{From: "C", To: "D", Cost: 15},
+		},
+	}
+	plain, _ := graph.NewFromStruct(gj)
+	if p := findResult(RunFloyd(plain), "A", "D").Paths[0]; strings.Join(p.Path, "") != "ABD" {
+		t.Fatalf("without node weights A->D = %v, want A B D", p.Path)
+	}
+	gj.NodeWeight = map[string]int{"B": 20}
+	g, err := graph.NewFromStruct(gj)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := RunFloyd(g)
+	ad := findResult(r, "A", "D")
+	if ad.Distance != 30 || strings.Join(ad.Paths[0].Path, "") != "ACD" {
+		t.Errorf("A->D = %d %v, want 30 [A C D]", ad.Distance, ad.Paths[0].Path)
+	}
+	if len(ad.Paths) != 2 || ad.Paths[1].Distance != 40 {
+		t.Errorf("A->D alternative through B should cost 10+20+10: %v", ad.Paths)
+	}
+	if ab := findResult(r, "A", "B"); ab.Distance != 10 {
+		t.Errorf("A->B = %d, want 10 (endpoints do not pay their weight)", ab.Distance)
+	}
+	r.FillViaNeighborPaths()
+	if via := findResult(r, "A", "D").ViaNeighborPaths; len(via) != 2 || via[0].Distance != 30 || via[1].Distance != 40 {
+		t.Errorf("A->D via-neighbor paths = %v, want distances 30 and 40", via)
+	}
+	single, err := RunFloydWithOptions(g, &Options{SinglePathOnly: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p := findResult(single, "A", "D").Paths[0]; strings.Join(p.Path, "") != "ACD" {
+		t.Errorf("SinglePathOnly A->D = %v, want [A C D]", p.Path)
+	}
+	job, _ := NewFloydJob(g)
+	job.Step(g.NumNodes())
+	if got := job.State().Dist; !reflect.DeepEqual(got, r.DistanceMatrix()) {
+		t.Errorf("FloydJob distances %v, want %v", got, r.DistanceMatrix())
+	}
+	row, _ := NewSourceRouter(g).From("A")
+	if d, _ := g.Index("D"); row[d].Distance != 30 {
+		t.Errorf("SourceRouter A->D = %d, want 30", row[d].Distance)
+	}
+}
+
+func TestRunFloyd_SymmetrizedDistancesMatch(t *testing.T) {
+	// A<->B both ways, B->C only one way.
+	g, _ := graph.NewFromStruct(&graph.GraphJSON{Edges: []graph.Edge{
+		{From: "A", To: "B", Cost: 10}, {From: "B", To: "A", Cost: 10}, {From: "B", To: "C", Cost: 30},
+	}})
+	if ca := findResult(RunFloyd(g), "C", "A"); IsReachable(ca.Distance) {
+		t.Fatalf("C->A should be unreachable before symmetrizing, got %d", ca.Distance)
+	}
+	r := RunFloyd(g.Symmetrize())
+	for _, pr := range r.Results {
+		if back := findResult(r, pr.To, pr.From); back.Distance != pr.Distance {
+			t.Errorf("%s->%s = %d but %s->%s = %d", pr.From, pr.To, pr.Distance, pr.To, pr.From, back.Distance)
+		}
+	}
+	if ac := findResult(r, "A", "C"); ac.Distance != 40 {
+		t.Errorf("A->C = %d, want 40", ac.Distance)
+	}
+}
+
+func TestRunFloyd_SingleNode(t *testing.T) {
+	g, err := graph.NewFromStruct(&graph.GraphJSON{Nodes: []string{"A"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := RunFloyd(g)
+	r.FillViaNeighborPaths()
+	if len(r.Results) != 1 {
+		t.Fatalf("got %d results, want 1", len(r.Results))
+	}
+	aa := r.Results[0]
+	if aa.From != "A" || aa.To != "A" || aa.Distance != 0 || len(aa.ViaNeighborPaths) != 0 {
+		t.Errorf("self-pair: %+v", aa)
+	}
+	if d, ok := r.Diameter(); !ok || d != 0 {
+		t.Errorf("Diameter = %d, %v; want 0", d, ok)
+	}
+	if j, err := RunJohnson(g); err != nil || len(j.Results) != 1 || j.Results[0].Distance != 0 {
+		t.Errorf("RunJohnson: %v, %v", j, err)
+	}
+	if got := DetailedResults(g, r.Results); len(got) != 0 {
+		t.Errorf("DetailedResults should skip the self-pair: %v", got)
+	}
+}
+
+func TestRunFloyd_NoNodes(t *testing.T) {
+	one, _ := graph.NewFromStruct(&graph.GraphJSON{Nodes: []string{"A"}})
+	g, _ := one.CopyWithoutNode(0)
+	for name, opts := range map[string]*Options{
+		"default":        nil,
+		"distances only": {DistancesOnly: true},
+		"hop penalty":    {HopPenalty: 1, GlobalPathBudget: 2},
+	} {
+		r, err := RunFloydWithOptions(g, opts)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		r.FillViaNeighborPaths()
+		if len(r.Results) != 0 {
+			t.Errorf("%s: got %d results, want none", name, len(r.Results))
+		}
+		if _, ok := r.Radius(); ok {
+			t.Errorf("%s: Radius of an empty graph should be undefined", name)
+		}
+	}
+	if r, err := RunJohnson(g); err != nil || len(r.Results) != 0 {
+		t.Errorf("RunJohnson: %v, %v", r, err)
+	}
+}
+
+func TestViaNeighbor_PerNeighborCap(t *testing.T) {
+	// Through B there are three paths to D of distance 3; through C a single one of distance 11.
+	edges := []graph.Edge{{From: "A", To: "B", Cost: 1}, {From: "A", To: "C", Cost: 1}, {From: "C", To: "D", Cost: 10}}
+	for _, x := range []string{"X1", "X2", "X3"} {
+		edges = append(edges, graph.Edge{From: "B", To: x, Cost: 1}, graph.Edge{From: x, To: "D", Cost: 1})
+	}
+	g, _ := graph.NewFromStruct(&graph.GraphJSON{Edges: edges})
+	firstHops := func(opts *Options) map[string]int {
+		r, err := RunFloydWithOptions(g, opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r.FillViaNeighborPaths()
+		count := make(map[string]int)
+		for _, p := range findResult(r, "A", "D").ViaNeighborPaths {
+			count[p.Path[1]]++
+		}
+		return count
+	}
+	if got := firstHops(nil); !reflect.DeepEqual(got, map[string]int{"B": 3}) {
+		t.Errorf("default: first hops %v, want B only", got)
+	}
+	if got := firstHops(&Options{PerNeighborCap: 2}); !reflect.DeepEqual(got, map[string]int{"B": 2, "C": 1}) {
+		t.Errorf("PerNeighborCap 2: first hops %v, want B:2 C:1", got)
+	}
+}
+
+func TestViaNeighbor_Diverse(t *testing.T) {
+	// Through B there are three paths to D of distance 3; through C one of distance 4.
+	edges := []graph.Edge{{From: "A", To: "B", Cost: 1}, {From: "A", To: "C", Cost: 1}, {From: "C", To: "D", Cost: 3}}
+	for _, x := range []string{"X1", "X2", "X3"} {
+		edges = append(edges, graph.Edge{From: "B", To: x, Cost: 1}, graph.Edge{From: x, To: "D", Cost: 1})
+	}
+	g, _ := graph.NewFromStruct(&graph.GraphJSON{Edges: edges})
+	via := func(opts *Options) []string {
+		r, err := RunFloydWithOptions(g, opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r.FillViaNeighborPaths()
+		var keys []string
+		for _, p := range findResult(r, "A", "D").ViaNeighborPaths {
+			keys = append(keys, JoinPathKey(p.Path))
+		}
+		return keys
+	}
+	allB := []string{"A|B|X1|D", "A|B|X2|D", "A|B|X3|D"}
+	if got := via(nil); !reflect.DeepEqual(got, allB) {
+		t.Errorf("default: got %v, want %v", got, allB)
+	}
+	if got := via(&Options{Diverse: true}); !reflect.DeepEqual(got, allB) {
+		t.Errorf("slack 0: got %v, want %v", got, allB)
+	}
+	want := []string{"A|B|X1|D", "A|B|X2|D", "A|C|D"}
+	if got := via(&Options{Diverse: true, DiverseSlack: 1}); !reflect.DeepEqual(got, want) {
+		t.Errorf("slack 1: got %v, want %v", got, want)
+	}
+}
+
+func TestReverseGraphTransposesDistances(t *testing.T) {
+	gj := &graph.GraphJSON{
+		Nodes: []string{"A", "B", "C", "D"},
+		Edges: []graph.Edge{
+			{From: "A", To: "B", Cost: 50},
+			{From: "B", To: "A", Cost: 80},
+			{From: "A", To: "C", Cost: 100},
+			{From: "B", To: "C", Cost: 20},
+			{From: "C", To: "D", Cost: 5},
+		},
+	}
+	g, _ := graph.NewFromStruct(gj)
+	orig := RunFloyd(g)
+	rev := RunFloyd(g.Reverse())
+	N := g.NumNodes()
+	for i := 0; i < N; i++ {
+		for j := 0; j < N; j++ {
+			if rev.dist[i][j] != orig.dist[j][i] {
+				t.Errorf("reverse dist[%d][%d]=%d, original dist[%d][%d]=%d", i, j, rev.dist[i][j], j, i, orig.dist[j][i])
+			}
+		}
+	}
+}
+
+// fanOutGraph is A fanning out to B, C, D which all lead to E with cost 1,
+// giving three equal-cost A->E paths.
+func fanOutGraph(tb testing.TB) *graph.Graph {
+	tb.Helper()
+	g, err := graph.NewFromStruct(&graph.GraphJSON{
+		Nodes: []string{"A", "B", "C", "D", "E"},
+		Edges: []graph.Edge{
+			{From: "A", To: "B", Cost: 1},
+			{From: "A", To: "C", Cost: 1},
+			{From: "A", To: "D", Cost: 1},
+			{From: "B", To: "E", Cost: 1},
+			{From: "C", To: "E", Cost: 1},
+			{From: "D", To: "E", Cost: 1},
+		},
+	})
+	if err != nil {
+		tb.Fatal(err)
+	}
+	return g
+}
+
+func TestJoinPathKey(t *testing.T) {
+	if k := JoinPathKey([]string{"A", "BC", "D"}); k != "A|BC|D" {
+		t.Errorf("got %q", k)
+	}
+	if k := JoinPathKey(nil); k != "" {
+		t.Errorf("empty path: got %q", k)
+	}
+}
+
+func TestCustomPathKey(t *testing.T) {
+	calls := 0
+	key := func(path []string) string {
+		calls++
+		return JoinPathKey(path)
+	}
+	r, err := RunFloydWithOptions(fanOutGraph(t), &Options{PathKey: key})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.FillViaNeighborPaths()
+	if calls == 0 {
+		t.Error("custom PathKey was not used")
+	}
+	want := RunFloyd(fanOutGraph(t))
+	want.FillViaNeighborPaths()
+	if !reflect.DeepEqual(r.Results, want.Results) {
+		t.Error("results with an equivalent custom key differ from the default")
+	}
+
+	// A key that merges every path keeps a single via-neighbor path per pair.
+	r, err = RunFloydWithOptions(fanOutGraph(t), &Options{PathKey: func([]string) string { return "" }})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.FillViaNeighborPaths()
+	if ae := findResult(r, "A", "E"); len(ae.ViaNeighborPaths) != 1 {
+		t.Errorf("A->E via-neighbor paths with a constant key: %v", ae.ViaNeighborPaths)
+	}
+}
+
+func BenchmarkPathKey_FanOut(b *testing.B) {
+	r := RunFloyd(fanOutGraph(b))
+	var paths [][]string
+	for _, pr := range r.Results {
+		for _, p := range pr.Paths {
+			paths = append(paths, p.Path)
+		}
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		for _, p := range paths {
+			_ = JoinPathKey(p)
+		}
+	}
+}
+
+func BenchmarkRunFloyd_FanOut(b *testing.B) {
+	g := fanOutGraph(b)
+	b.ReportAllocs()
+	for n := 0; n < b.N; n++ {
+		RunFloyd(g)
+	}
+}
+
+func TestRunFloydWithWeightFunc(t *testing.T) {
+	gj := &graph.GraphJSON{
+		Nodes: []string{"A", "B", "C", "D"},
+		Edges: []graph.Edge{
+			{From: "A", To: "B", Cost: 10},
+			{From: "B", To: "D", Cost: 10},
+			{From: "A", To: "C", Cost: 15},
+			{From: "C", To: "D", Cost: 10},
+		},
+	}
+	g, _ := graph.NewFromStruct(gj)
+	identity := RunFloydWithWeightFunc(g, func(src, i, j, base int) int { return base })
+	plain := RunFloyd(g)
+	for k := range plain.Results {
+		if identity.Results[k].Distance != plain.Results[k].Distance {
+			t.Errorf("identity wf %s->%s: got %d, want %d", plain.Results[k].From, plain.Results[k].To,
+				identity.Results[k].Distance, plain.Results[k].Distance)
+		}
+	}
+
+	a, _ := g.Index("A")
+	b, _ := g.Index("B")
+	d, _ := g.Index("D")
+	// Source A doubles B->D, so A->B->D (30) loses to A->C->D (25); B is unaffected.
+	r := RunFloydWithWeightFunc(g, func(src, i, j, base int) int {
+		if src == a && i == b && j == d {
+			return base * 2
+		}
+		return base
+	})
+	ad := findResult(r, "A", "D")
+	if ad == nil || ad.Distance != 25 || len(ad.Paths) == 0 || ad.Paths[0].Path[1] != "C" {
+		t.Errorf("A->D with doubled B->D: expected 25 via C, got %v", ad)
+	}
+	bd := findResult(r, "B", "D")
+	if bd == nil || bd.Distance != 10 {
+		t.Errorf("B->D should keep base weight 10, got %v", bd)
+	}
+}
+
+func TestRunFloydWithOptions_GlobalPathBudget(t *testing.T) {
+	g := graph.GridGraph(5, 5)
+	full := RunFloyd(g)
+	reachable, unbounded := 0, 0
+	for _, pr := range full.Results {
+		if pr.Distance >= 0 {
+			reachable++
+		}
+		unbounded += len(pr.Paths)
+	}
+	for _, budget := range []int{reachable / 2, reachable, reachable + 40} {
+		r, err := RunFloydWithOptions(g, &Options{GlobalPathBudget: budget})
+		if err != nil {
+			t.Fatal(err)
+		}
+		total := 0
+		for k, pr := range r.Results {
+			total += len(pr.Paths)
+			if pr.Distance != full.Results[k].Distance {
+				t.Fatalf("budget %d: distance of %s->%s changed", budget, pr.From, pr.To)
+			}
+			if budget >= reachable && pr.Distance >= 0 && len(pr.Paths) == 0 {
+				t.Errorf("budget %d: reachable pair %s->%s got no path", budget, pr.From, pr.To)
+			}
+		}
+		if total > budget {
+			t.Errorf("budget %d: enumerated %d paths", budget, total)
+		}
+		if budget < unbounded && total != budget {
+			t.Errorf("budget %d: expected the budget to be used fully, got %d", budget, total)
+		}
+	}
+}
+
+func TestRunFloydWithOptions_InvalidGraph(t *testing.T) {
+	g := &graph.Graph{
+		Nodes:       []string{"A", "B"},
+		NameToIndex: map[string]int{"A": 0, "B": 1},
+		AdjMatrix:   [][]int{{0, 1}, {0}}, // ragged
+	}
+	if _, err := RunFloydWithOptions(g, nil); err == nil {
+		t.Error("expected error for ragged AdjMatrix")
+	}
+}
+
+func TestRunFloyd_Deterministic(t *testing.T) {
+	g := graph.GridGraph(3, 4)
+	a := RunFloyd(g)
+	a.FillViaNeighborPaths()
+	b := RunFloyd(g)
+	b.FillViaNeighborPaths()
+	if !reflect.DeepEqual(a.Results, b.Results) {
+		t.Fatal("two runs on the same graph produced different Results")
+	}
+	// Equal-distance paths are ordered by node index sequence: r0c0 -> r1c1 via r0c1 before r1c0.
+	pr := findResult(a, "r0c0", "r1c1")
+	if len(pr.Paths) != 2 || JoinPathKey(pr.Paths[0].Path) != "r0c0|r0c1|r1c1" || JoinPathKey(pr.Paths[1].Path) != "r0c0|r1c0|r1c1" {
+		t.Errorf("r0c0->r1c1 paths = %v", pr.Paths)
+	}
+}
+
+func TestRunFloydWithOptions_Limits(t *testing.T) {
+	gj := &graph.GraphJSON{Edges: []graph.Edge{
+		{From: "A", To: "B", Cost: 2}, {From: "B", To: "D", Cost: 2},
+		{From: "A", To: "C", Cost: 2}, {From: "C", To: "D", Cost: 2},
+		{From: "A", To: "D", Cost: 5},
+	}}
+	opts := &graph.Options{Limits: &graph.Limits{MinCost: 2, MaxCost: 10}}
+	g, err := graph.NewFromStructWithOptions(gj, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := g.AddEdge("B", "C", 11); err == nil {
+		t.Error("AddEdge: expected error for cost above Limits.MaxCost")
+	}
+	if err := g.AddEdge("B", "C", 1); err == nil {
+		t.Error("AddEdge: expected error for cost below Limits.MinCost")
+	}
+	gj.Edges = append(gj.Edges, graph.Edge{From: "D", To: "A", Cost: 50})
+	if _, err := graph.NewFromStructWithOptions(gj, opts); err == nil {
+		t.Error("expected error for cost above Limits.MaxCost")
+	}
+
+	if pr := findResult(RunFloyd(g), "A", "D"); len(pr.Paths) != 3 {
+		t.Fatalf("default limits: got %d paths, want 3", len(pr.Paths))
+	}
+	r, err := RunFloydWithOptions(g, &Options{Limits: &graph.Limits{MaxShortestPaths: 2}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pr := findResult(r, "A", "D"); len(pr.Paths) != 2 || pr.Distance != 4 {
+		t.Errorf("MaxShortestPaths 2: got %v", pr.Paths)
+	}
+}
+
+func TestRunFloydWithOptions_Timings(t *testing.T) {
+	g := fanOutGraph(t)
+	if RunFloyd(g).EnumerationTimings() != nil {
+		t.Error("timings should be nil unless enabled")
+	}
+	r, err := RunFloydWithOptions(g, &Options{Timings: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	timings := r.EnumerationTimings()
+	if _, ok := timings[[2]string{"A", "E"}]; !ok {
+		t.Errorf("no timing for the fan-out pair A->E: %v", timings)
+	}
+	if _, ok := timings[[2]string{"E", "A"}]; ok {
+		t.Error("unreachable pair E->A should not be timed")
+	}
+}
+
+func TestUnreachableNeverInf(t *testing.T) {
+	r := RunFloyd(weightedGraph(t)) // C reaches nothing
+	r.FillViaNeighborPaths()
+	for _, pr := range r.Results {
+		if pr.Distance == math.MaxInt {
+			t.Errorf("%s->%s: Distance is MaxInt", pr.From, pr.To)
+		}
+		for _, p := range append(pr.Paths, pr.ViaNeighborPaths...) {
+			if p.Distance == math.MaxInt {
+				t.Errorf("%s->%s: path distance is MaxInt", pr.From, pr.To)
+			}
+		}
+	}
+	m := r.DistanceMatrix()
+	for i, row := range m {
+		for j, d := range row {
+			if d == math.MaxInt {
+				t.Errorf("DistanceMatrix[%d][%d] is MaxInt", i, j)
+			}
+		}
+	}
+	if m[2][0] != Unreachable || IsReachable(m[2][0]) || !IsReachable(m[0][2]) {
+		t.Errorf("C->A = %d, A->C = %d", m[2][0], m[0][2])
+	}
+	q, err := r.Query([][2]string{{"C", "A"}})
+	if err != nil || q[0].Distance != Unreachable {
+		t.Errorf("Query C->A = %v, %v", q, err)
+	}
+}
+
+func TestRunFloyd_NonTransit(t *testing.T) {
+	// A->S->B costs 2 but S is a stub; A->C->B costs 20.
+	g, err := graph.NewFromStruct(&graph.GraphJSON{
+		Edges: []graph.Edge{
+			{From: "A", To: "S", Cost: 1}, {From: "S", To: "B", Cost: 1},
+			{From: "A", To: "C", Cost: 10}, {From: "C", To: "B", Cost: 10},
+		},
+		NonTransit: []string{"S"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := RunFloyd(g)
+	r.FillViaNeighborPaths()
+	ab := findResult(r, "A", "B")
+	if ab.Distance != 20 || len(ab.Paths) != 1 || JoinPathKey(ab.Paths[0].Path) != "A|C|B" {
+		t.Errorf("A->B: got %d %v, want 20 via C only", ab.Distance, ab.Paths)
+	}
+	for _, p := range ab.ViaNeighborPaths {
+		if JoinPathKey(p.Path) == "A|S|B" {
+			t.Error("A->B via-neighbor path goes through non-transit S")
+		}
+	}
+	if d := findResult(r, "A", "S").Distance; d != 1 {
+		t.Errorf("A->S = %d, want 1 (S is still a destination)", d)
+	}
+	if d := findResult(r, "S", "B").Distance; d != 1 {
+		t.Errorf("S->B = %d, want 1 (S is still a source)", d)
+	}
+	if _, err := graph.NewFromStruct(&graph.GraphJSON{Nodes: []string{"A"}, NonTransit: []string{"X"}}); err == nil {
+		t.Error("expected error for unknown non-transit node")
+	}
+}
+
+func TestRunFloydWithOptions_DistancesOnly(t *testing.T) {
+	g := graph.GridGraph(4, 4)
+	full := RunFloyd(g)
+	r, err := RunFloydWithOptions(g, &Options{DistancesOnly: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for k, pr := range r.Results {
+		if pr.Distance != full.Results[k].Distance {
+			t.Errorf("%s->%s: distance %d, want %d", pr.From, pr.To, pr.Distance, full.Results[k].Distance)
+		}
+		if pr.Paths != nil {
+			t.Errorf("%s->%s: Paths should be nil", pr.From, pr.To)
+		}
+	}
+	// Methods that need predecessors still work.
+	if n := r.ShortestPathCount("r0c0", "r3c3"); n != 20 {
+		t.Errorf("ShortestPathCount = %d, want 20", n)
+	}
+}
+
+func BenchmarkRunFloyd_DistancesOnly(b *testing.B) {
+	g := graph.GridGraph(6, 6)
+	for _, tc := range []struct {
+		name string
+		opts *Options
+	}{
+		{"Full", nil},
+		{"DistancesOnly", &Options{DistancesOnly: true}},
+	} {
+		b.Run(tc.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, err := RunFloydWithOptions(g, tc.opts); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func TestRunFloydWithOptions_SinglePathOnly(t *testing.T) {
+	g := graph.RandomGraph(16, 48, 50, 1)
+	full := RunFloyd(g)
+	single, err := RunFloydWithOptions(g, &Options{SinglePathOnly: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if single.pred != nil {
+		t.Error("SinglePathOnly should not build the predecessor lists")
+	}
+	checkSinglePaths := func(r *AllPairsResult, want *AllPairsResult) {
+		t.Helper()
+		for k, pr := range r.Results {
+			if pr.Distance != want.Results[k].Distance {
+				t.Fatalf("%s -> %s: distance %d, want %d", pr.From, pr.To, pr.Distance, want.Results[k].Distance)
+			}
+			if !IsReachable(pr.Distance) {
+				if len(pr.Paths) != 0 {
+					t.Fatalf("%s -> %s: unreachable pair has paths %v", pr.From, pr.To, pr.Paths)
+				}
+				continue
+			}
+			if len(pr.Paths) != 1 {
+				t.Fatalf("%s -> %s: got %d paths, want 1", pr.From, pr.To, len(pr.Paths))
+			}
+			p := pr.Paths[0].Path
+			if p[0] != pr.From || p[len(p)-1] != pr.To {
+				t.Fatalf("%s -> %s: path %v has the wrong ends", pr.From, pr.To, p)
+			}
+			sum := 0
+			for h := 1; h < len(p); h++ {
+				i, _ := g.Index(p[h-1])
+				j, _ := g.Index(p[h])
+				if g.Cost(i, j) == 0 {
+					t.Fatalf("%s -> %s: path %v uses missing edge %s -> %s", pr.From, pr.To, p, p[h-1], p[h])
+				}
+				sum += g.Cost(i, j)
+			}
+			if sum != pr.Distance {
+				t.Fatalf("%s -> %s: path %v costs %d, want %d", pr.From, pr.To, p, sum, pr.Distance)
+			}
+		}
+	}
+	checkSinglePaths(single, full)
+
+	// Updates keep the single path consistent: a decrease is applied in place, an increase recomputes.
+	for _, w := range []int{1, 60} {
+		if err := single.UpdateEdge("n0", "n8", w); err != nil {
+			t.Fatal(err)
+		}
+		if err := full.UpdateEdge("n0", "n8", w); err != nil {
+			t.Fatal(err)
+		}
+		checkSinglePaths(single, full)
+	}
+}
+
+func TestNormalizedDistanceMatrix(t *testing.T) {
+	// weightedGraph: A->B 50, B->A 80, A->C 70 via B, B->C 20, B->A->... and C reaches nothing.
+	m, names := RunFloyd(weightedGraph(t)).NormalizedDistanceMatrix()
+	if !reflect.DeepEqual(names, []string{"A", "B", "C"}) {
+		t.Fatalf("names = %v", names)
+	}
+	for i := range names {
+		if m[i][i] != 0 {
+			t.Errorf("self pair %s = %v, want 0", names[i], m[i][i])
+		}
+	}
+	// The longest finite distance is B->A (80); B->C is 20.
+	if m[1][0] != 1 {
+		t.Errorf("B->A = %v, want 1", m[1][0])
+	}
+	if m[1][2] != 0.25 {
+		t.Errorf("B->C = %v, want 0.25", m[1][2])
+	}
+	if !math.IsNaN(m[2][0]) {
+		t.Errorf("C->A = %v, want NaN", m[2][0])
+	}
+}
+
+func TestRunFloydWithOptions_NodeOrder(t *testing.T) {
+	g := weightedGraph(t)
+	r, err := RunFloydWithOptions(g, &Options{NodeOrder: []string{"C", "B", "A"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, pr := range r.Results {
+		got = append(got, pr.From+pr.To)
+	}
+	want := []string{"CC", "CB", "CA", "BC", "BB", "BA", "AC", "AB", "AA"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("order = %v, want %v", got, want)
+	}
+	// Index-based updates still land on the right pairs.
+	if err := r.UpdateEdge("A", "C", 10); err != nil {
+		t.Fatal(err)
+	}
+	r.FillViaNeighborPaths()
+	if ac := findResult(r, "A", "C"); ac.Distance != 10 || JoinPathKey(ac.Paths[0].Path) != "A|C" {
+		t.Errorf("A->C after update: %+v", ac)
+	}
+	if ba := findResult(r, "B", "A"); ba.Distance != 80 || len(ba.ViaNeighborPaths) == 0 {
+		t.Errorf("B->A: %+v", ba)
+	}
+	for _, order := range [][]string{{"A", "B"}, {"A", "B", "Z"}, {"A", "B", "B"}} {
+		if _, err := RunFloydWithOptions(g, &Options{NodeOrder: order}); err == nil {
+			t.Errorf("NodeOrder %v: expected error", order)
+		}
+	}
+}
+
+func TestRunFloydWithOptions_HopPenalty(t *testing.T) {
+	// A->B->D costs 100 in 2 hops; A->C->E->D costs 95 in 3 hops.
+	g, err := graph.NewFromStruct(&graph.GraphJSON{Edges: []graph.Edge{
+		{From: "A", To: "B", Cost: 50}, {From: "B", To: "D", Cost: 50},
+		{From: "A", To: "C", Cost: 30}, {From: "C", To: "E", Cost: 30}, {From: "E", To: "D", Cost: 35},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pr := findResult(RunFloyd(g), "A", "D"); JoinPathKey(pr.Paths[0].Path) != "A|C|E|D" {
+		t.Fatalf("no penalty: got %v, want A|C|E|D", pr.Paths[0])
+	}
+	r, err := RunFloydWithOptions(g, &Options{HopPenalty: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	pr := findResult(r, "A", "D")
+	best := pr.Paths[0]
+	if JoinPathKey(best.Path) != "A|B|D" || best.Distance != 120 || pr.Distance != 120 {
+		t.Errorf("penalty 10: got %v, want A|B|D with distance 120", best)
+	}
+	if raw := best.Metrics[graph.CostMetric]; raw != 100 {
+		t.Errorf("raw cost = %d, want 100", raw)
+	}
+	if g.Cost(0, 1) != 50 {
+		t.Error("input graph was modified")
+	}
+}
@@ -0,0 +1,120 @@
+package floyd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jursonmo/pathroute/graph"
+)
+
+// FormatPathTrace renders path traceroute-style, one line per hop with the cost of the incoming
+// edge and the cumulative distance:
+//
+//	1  A  (+0, total 0)
+//	2  B  (+50, total 50)
+//	3  C  (+20, total 70)
+//
+// Hops over a missing edge (or unknown names) are shown with +0.
+func FormatPathTrace(g *graph.Graph, path []string) string {
+	var b strings.Builder
+	total := 0
+	for n, name := range path {
+		w := 0
+		if n > 0 {
+			w = hopCost(g, path[n-1], name)
+		}
+		total += w
+		if n > 0 {
+			b.WriteByte('\n')
+		}
+		fmt.Fprintf(&b, "%d  %s  (+%d, total %d)", n+1, name, w, total)
+	}
+	return b.String()
+}
+
+// hopCost returns the cost of edge from -> to, or 0 if either name is unknown or there is no edge.
+func hopCost(g *graph.Graph, from, to string) int {
+	i, okA := g.Index(from)
+	j, okB := g.Index(to)
+	if !okA || !okB {
+		return 0
+	}
+	return g.Cost(i, j)
+}
+
+// Hop is one edge of a path with its cost.
+type Hop struct {
+	From   string `json:"from"`
+	To     string `json:"to"`
+	Weight int    `json:"weight"`
+}
+
+// DetailedPath is a path spelled out hop by hop, so consumers don't need the graph to recover
+// the edge costs.
+type DetailedPath struct {
+	Distance int   `json:"distance"`
+	Hops     []Hop `json:"hops"`
+}
+
+// DetailedPair is the detailed form of a PairResult's shortest paths.
+type DetailedPair struct {
+	From     string         `json:"from"`
+	To       string         `json:"to"`
+	Distance int            `json:"distance"`
+	Paths    []DetailedPath `json:"paths"`
+}
+
+// DetailedResults converts results to their detailed form, taking hop weights from g. Self-pairs
+// are skipped; unreachable pairs have Distance Unreachable and no paths.
+func DetailedResults(g *graph.Graph, results []PairResult) []DetailedPair {
+	out := make([]DetailedPair, 0, len(results))
+	for _, pr := range results {
+		if pr.From == pr.To {
+			continue
+		}
+		dp := DetailedPair{From: pr.From, To: pr.To, Distance: pr.Distance, Paths: []DetailedPath{}}
+		for _, p := range pr.Paths {
+			hops := make([]Hop, 0, len(p.Path))
+			for n := 1; n < len(p.Path); n++ {
+				hops = append(hops, Hop{From: p.Path[n-1], To: p.Path[n], Weight: hopCost(g, p.Path[n-1], p.Path[n])})
+			}
+			dp.Paths = append(dp.Paths, DetailedPath{Distance: p.Distance, Hops: hops})
+		}
+		out = append(out, dp)
+	}
+	return out
+}
+
+// RouteRow is one pair flattened for loading into a database table. PathJoined is the first
+// shortest path and ViaPathJoined the best via-neighbor path (empty until FillViaNeighborPaths
+// has run), both joined by "->"; Hops is the edge count of the first shortest path.
+type RouteRow struct {
+	From          string `json:"from"`
+	To            string `json:"to"`
+	Distance      int    `json:"distance"`
+	Hops          int    `json:"hops"`
+	PathJoined    string `json:"path"`
+	ViaPathJoined string `json:"via_path"`
+}
+
+// Rows returns one RouteRow per reachable pair of distinct nodes, in the order of r.Results.
+func (r *AllPairsResult) Rows() []RouteRow {
+	var rows []RouteRow
+	for _, pr := range r.Results {
+		i, _ := r.g.Index(pr.From)
+		j, _ := r.g.Index(pr.To)
+		if i == j || r.dist[i][j] == Inf {
+			continue
+		}
+		row := RouteRow{From: pr.From, To: pr.To, Distance: pr.Distance}
+		if len(pr.Paths) > 0 {
+			row.Hops = len(pr.Paths[0].Path) - 1
+			row.PathJoined = strings.Join(pr.Paths[0].Path, "->")
+		}
+		if len(pr.ViaNeighborPaths) > 0 {
+			row.ViaPathJoined = strings.Join(pr.ViaNeighborPaths[0].Path, "->")
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
@@ -0,0 +1,31 @@
+package floyd
+
+import "testing"
+
+func TestFormatPathTrace(t *testing.T) {
+	got := FormatPathTrace(weightedGraph(t), []string{"A", "B", "C"})
+	want := "1  A  (+0, total 0)\n2  B  (+50, total 50)\n3  C  (+20, total 70)"
+	if got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestRows(t *testing.T) {
+	r := RunFloyd(weightedGraph(t))
+	r.FillViaNeighborPaths()
+	rows := r.Rows()
+	// A->B, A->C, B->A, B->C; nothing is reachable from C.
+	if len(rows) != 4 {
+		t.Fatalf("got %d rows, want 4: %v", len(rows), rows)
+	}
+	want := RouteRow{From: "A", To: "C", Distance: 70, Hops: 2, PathJoined: "A->B->C", ViaPathJoined: "A->B->C"}
+	for _, row := range rows {
+		if row.From == "A" && row.To == "C" {
+			if row != want {
+				t.Errorf("A->C row = %+v, want %+v", row, want)
+			}
+			return
+		}
+	}
+	t.Error("no A->C row")
+}
@@ -0,0 +1,108 @@
+package floyd
+
+import "github.com/jursonmo/pathroute/graph"
+
+// floydCore runs the Floyd-Warshall relaxation over n nodes with edge weights w(i, j) (0 = no edge)
+// and returns the distance matrix, using inf for unreachable pairs. It is shared by the int Graph
+// path (RunFloyd) and the generic Weighted path. If next is non-nil (n x n) it is filled with the
+// first hop of one shortest i->j path, or -1 when there is none. Nodes k with skip[k] set are
+// never used as intermediate nodes; skip may be nil. If nw is non-nil, going through k as an
+// intermediate node costs nw[k] on top of the edges.
+func floydCore[W graph.Weight](n int, w func(i, j int) W, inf W, next [][]int, skip []bool, nw []W) [][]W {
+	dist := make([][]W, n)
+	for i := 0; i < n; i++ {
+		dist[i] = make([]W, n)
+		for j := 0; j < n; j++ {
+			dist[i][j] = inf
+			if next != nil {
+				next[i][j] = -1
+			}
+			if i == j {
+				dist[i][j] = 0
+				if next != nil {
+					next[i][j] = j
+				}
+			} else if c := w(i, j); c > 0 {
+				dist[i][j] = c
+				if next != nil {
+					next[i][j] = j
+				}
+			}
+		}
+	}
+	for k := 0; k < n; k++ {
+		if skip == nil || !skip[k] {
+			var through W
+			if nw != nil {
+				through = nw[k]
+			}
+			relaxThrough(dist, k, through, inf, next)
+		}
+	}
+	return dist
+}
+
+// relaxThrough is one round of the Floyd-Warshall relaxation: it shortens every dist[i][j] that
+// is improved by going through k at an extra cost of through, updating next alongside if it is
+// non-nil.
+func relaxThrough[W graph.Weight](dist [][]W, k int, through, inf W, next [][]int) {
+	for i := range dist {
+		if dist[i][k] == inf {
+			continue
+		}
+		for j := range dist {
+			if dist[k][j] == inf {
+				continue
+			}
+			if d := dist[i][k] + through + dist[k][j]; d < dist[i][j] {
+				dist[i][j] = d
+				if next != nil {
+					next[i][j] = next[i][k]
+				}
+			}
+		}
+	}
+}
+
+// WeightedResult holds all-pairs shortest distances over a graph.Weighted of any weight type.
+type WeightedResult[W graph.Weight] struct {
+	g    *graph.Weighted[W]
+	inf  W
+	dist [][]W
+	next [][]int
+}
+
+// RunFloydWeighted computes all-pairs shortest distances over g with the same relaxation as
+// RunFloyd's SinglePathOnly mode, honoring non-transit nodes and node weights. inf is the value
+// used internally for unreachable pairs; it must exceed any finite path weight (e.g. Inf for
+// ints, math.Inf(1) for floats).
+func RunFloydWeighted[W graph.Weight](g *graph.Weighted[W], inf W) *WeightedResult[W] {
+	dist, next := floydNextHops(g, inf)
+	return &WeightedResult[W]{g: g, inf: inf, dist: dist, next: next}
+}
+
+// Distance returns the shortest distance from -> to; ok is false if a name is unknown or the
+// pair is unreachable.
+func (r *WeightedResult[W]) Distance(from, to string) (W, bool) {
+	i, ok1 := r.g.Index(from)
+	j, ok2 := r.g.Index(to)
+	if !ok1 || !ok2 || r.dist[i][j] == r.inf {
+		return 0, false
+	}
+	return r.dist[i][j], true
+}
+
+// Path returns one shortest path from -> to, or nil if a name is unknown or the pair is unreachable.
+func (r *WeightedResult[W]) Path(from, to string) []string {
+	i, ok1 := r.g.Index(from)
+	j, ok2 := r.g.Index(to)
+	if !ok1 || !ok2 || r.next[i][j] < 0 {
+		return nil
+	}
+	path := []string{r.g.Name(i)}
+	for u := i; u != j; {
+		u = r.next[u][j]
+		path = append(path, r.g.Name(u))
+	}
+	return path
+}
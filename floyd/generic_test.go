@@ -0,0 +1,74 @@
+package floyd
+
+import (
+	"math"
+	"testing"
+
+	"github.com/jursonmo/pathroute/graph"
+)
+
+func TestRunFloydWeighted_Int(t *testing.T) {
+	g, err := graph.NewWeighted(nil, []graph.WeightedEdge[int]{
+		{From: "A", To: "B", Weight: 50},
+		{From: "B", To: "C", Weight: 20},
+		{From: "A", To: "C", Weight: 100},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := RunFloydWeighted(g, Inf)
+	if d, ok := r.Distance("A", "C"); !ok || d != 70 {
+		t.Errorf("A->C: got %d %v, want 70", d, ok)
+	}
+	if p := r.Path("A", "C"); len(p) != 3 || p[1] != "B" {
+		t.Errorf("A->C path: got %v", p)
+	}
+	if _, ok := r.Distance("C", "A"); ok {
+		t.Error("C->A should be unreachable")
+	}
+}
+
+func TestRunFloydWeighted_Float(t *testing.T) {
+	g, err := graph.NewWeighted([]string{"A", "B", "C", "D"}, []graph.WeightedEdge[float64]{
+		{From: "A", To: "B", Weight: 0.5},
+		{From: "B", To: "D", Weight: 0.25},
+		{From: "A", To: "C", Weight: 0.3},
+		{From: "C", To: "D", Weight: 0.5},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := RunFloydWeighted(g, math.Inf(1))
+	if d, ok := r.Distance("A", "D"); !ok || d != 0.75 {
+		t.Errorf("A->D: got %v %v, want 0.75", d, ok)
+	}
+	if p := r.Path("A", "D"); len(p) != 3 || p[1] != "B" {
+		t.Errorf("A->D path: got %v, want [A B D]", p)
+	}
+	if p := r.Path("D", "A"); p != nil {
+		t.Errorf("D->A should have no path, got %v", p)
+	}
+	if _, err := graph.NewWeighted(nil, []graph.WeightedEdge[float64]{{From: "A", To: "B", Weight: -1}}); err == nil {
+		t.Error("expected error for non-positive weight")
+	}
+}
+
+func TestRunFloydWeighted_UnsignedNonTransit(t *testing.T) {
+	g, err := graph.NewWeighted(nil, []graph.WeightedEdge[uint16]{
+		{From: "A", To: "B", Weight: 1},
+		{From: "B", To: "C", Weight: 1},
+		{From: "A", To: "D", Weight: 5},
+		{From: "D", To: "C", Weight: 5},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	g.NonTransit = map[string]bool{"B": true}
+	r := RunFloydWeighted(g, math.MaxUint16)
+	if d, ok := r.Distance("A", "C"); !ok || d != 10 {
+		t.Errorf("A->C: got %d %v, want 10 avoiding non-transit B", d, ok)
+	}
+	if p := r.Path("A", "C"); len(p) != 3 || p[1] != "D" {
+		t.Errorf("A->C path: got %v, want [A D C]", p)
+	}
+}
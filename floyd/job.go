@@ -0,0 +1,124 @@
+package floyd
+
+import (
+	"fmt"
+
+	"github.com/jursonmo/pathroute/graph"
+)
+
+// FloydJob is a resumable all-pairs computation for environments with a CPU-time limit per call:
+// each Step advances the Floyd-Warshall k loop by a bounded number of rounds, and State can be
+// persisted between calls and restored with ResumeFloydJob.
+type FloydJob struct {
+	g     *graph.Graph
+	skip  []bool
+	nw    []int
+	state FloydJobState
+}
+
+// FloydJobState is the serializable progress of a FloydJob: the next k to relax through and the
+// partial distance matrix, in which pairs not reached yet hold Unreachable.
+type FloydJobState struct {
+	K    int     `json:"k"`
+	Dist [][]int `json:"dist"`
+}
+
+// NewFloydJob starts a job over g, which must not change until the job is done.
+func NewFloydJob(g *graph.Graph) (*FloydJob, error) {
+	if err := g.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid graph: %w", err)
+	}
+	adj := g.CostMatrix()
+	dist := make([][]int, len(adj))
+	for i, row := range adj {
+		dist[i] = make([]int, len(row))
+		for j, w := range row {
+			switch {
+			case i == j:
+			case w > 0:
+				dist[i][j] = w
+			default:
+				dist[i][j] = Inf
+			}
+		}
+	}
+	return &FloydJob{g: g, skip: nonTransitMask(g), nw: nodeWeightVector(g), state: FloydJobState{Dist: dist}}, nil
+}
+
+// ResumeFloydJob continues a job over g from a state saved by State.
+func ResumeFloydJob(g *graph.Graph, st FloydJobState) (*FloydJob, error) {
+	if err := g.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid graph: %w", err)
+	}
+	N := g.NumNodes()
+	if len(st.Dist) != N || st.K < 0 || st.K > N {
+		return nil, fmt.Errorf("job state (k=%d, %d rows) does not match a graph of %d nodes", st.K, len(st.Dist), N)
+	}
+	dist := make([][]int, N)
+	for i, row := range st.Dist {
+		if len(row) != N {
+			return nil, fmt.Errorf("job state row %d has %d entries, want %d", i, len(row), N)
+		}
+		dist[i] = make([]int, N)
+		for j, d := range row {
+			if d == Unreachable {
+				d = Inf
+			}
+			dist[i][j] = d
+		}
+	}
+	return &FloydJob{g: g, skip: nonTransitMask(g), nw: nodeWeightVector(g), state: FloydJobState{K: st.K, Dist: dist}}, nil
+}
+
+// Step relaxes through up to maxK more intermediate nodes and reports whether all have been done.
+func (j *FloydJob) Step(maxK int) (done bool) {
+	dist := j.state.Dist
+	for n := 0; n < maxK && j.state.K < len(dist); n++ {
+		if k := j.state.K; j.skip == nil || !j.skip[k] {
+			through := 0
+			if j.nw != nil {
+				through = j.nw[k]
+			}
+			relaxThrough(dist, k, through, Inf, nil)
+		}
+		j.state.K++
+	}
+	return j.state.K == len(dist)
+}
+
+// State returns a copy of the job's progress for persisting between calls.
+func (j *FloydJob) State() FloydJobState {
+	dist := make([][]int, len(j.state.Dist))
+	for i, row := range j.state.Dist {
+		dist[i] = make([]int, len(row))
+		for k, d := range row {
+			if d == Inf {
+				d = Unreachable
+			}
+			dist[i][k] = d
+		}
+	}
+	return FloydJobState{K: j.state.K, Dist: dist}
+}
+
+// Result returns the same result as RunFloyd(g) once Step has reported done.
+func (j *FloydJob) Result() (*AllPairsResult, error) {
+	dist := j.state.Dist
+	if j.state.K < len(dist) {
+		return nil, fmt.Errorf("job not done: %d of %d rounds", j.state.K, len(dist))
+	}
+	g := j.g
+	maxPaths := g.Limits.Resolved().MaxShortestPaths
+	N := len(dist)
+	results := make([]PairResult, 0, N*N)
+	for i := 0; i < N; i++ {
+		for k := 0; k < N; k++ {
+			var paths []PathDist
+			if dist[i][k] != Inf {
+				paths = KShortestSimplePaths(g, i, k, maxPaths)
+			}
+			results = append(results, newPairResult(g, i, k, dist[i][k], paths))
+		}
+	}
+	return &AllPairsResult{Results: results, g: g, dist: dist, pred: predMatrix(g, dist), limits: g.Limits}, nil
+}
@@ -0,0 +1,91 @@
+package floyd
+
+import (
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/jursonmo/pathroute/graph"
+)
+
+func TestFloydJob_StepwiseMatchesFullRun(t *testing.T) {
+	g := graph.RandomGraph(12, 36, 50, 1)
+	want := RunFloyd(g)
+
+	job, err := NewFloydJob(g)
+	if err != nil {
+		t.Fatal(err)
+	}
+	steps := 0
+	for done := false; !done; steps++ {
+		if _, err := job.Result(); err == nil {
+			t.Fatal("Result before done should fail")
+		}
+		// Persist and restore between steps, as separate invocations would.
+		data, err := json.Marshal(job.State())
+		if err != nil {
+			t.Fatal(err)
+		}
+		var st FloydJobState
+		if err := json.Unmarshal(data, &st); err != nil {
+			t.Fatal(err)
+		}
+		if job, err = ResumeFloydJob(g, st); err != nil {
+			t.Fatal(err)
+		}
+		done = job.Step(1)
+	}
+	if steps != g.NumNodes() {
+		t.Errorf("took %d steps, want %d", steps, g.NumNodes())
+	}
+	got, err := job.Result()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got.DistanceMatrix(), want.DistanceMatrix()) {
+		t.Error("stepwise distances differ from RunFloyd")
+	}
+	if !reflect.DeepEqual(got.Results, want.Results) {
+		t.Error("stepwise results differ from RunFloyd")
+	}
+	if _, err := ResumeFloydJob(weightedGraph(t), job.State()); err == nil {
+		t.Error("expected error resuming with a different graph size")
+	}
+}
+
+func TestFloydJob_StateUnreachable(t *testing.T) {
+	g := weightedGraph(t) // C reaches nothing
+	job, err := NewFloydJob(g)
+	if err != nil {
+		t.Fatal(err)
+	}
+	job.Step(1)
+	data, err := json.Marshal(job.State())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), strconv.Itoa(Inf)) {
+		t.Errorf("state leaks Inf: %s", data)
+	}
+	var st FloydJobState
+	if err := json.Unmarshal(data, &st); err != nil {
+		t.Fatal(err)
+	}
+	c, _ := g.Index("C")
+	if st.Dist[c][0] != Unreachable {
+		t.Errorf("C->A in state = %d, want Unreachable", st.Dist[c][0])
+	}
+	if job, err = ResumeFloydJob(g, st); err != nil {
+		t.Fatal(err)
+	}
+	job.Step(g.NumNodes())
+	got, err := job.Result()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := RunFloyd(g); !reflect.DeepEqual(got.dist, want.dist) || !reflect.DeepEqual(got.Results, want.Results) {
+		t.Error("resumed job differs from RunFloyd")
+	}
+}
@@ -0,0 +1,156 @@
+package floyd
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+	"sync"
+
+	"github.com/jursonmo/pathroute/graph"
+)
+
+// RunJohnson computes all pairs with Johnson's algorithm: Bellman-Ford from a virtual source
+// yields potentials h that reweight every edge to w(u,v)+h(u)-h(v) >= 0, then one Dijkstra per
+// source runs on the reweighted graph. Unlike RunFloyd it accepts negative edge costs (any
+// non-zero AdjMatrix entry is an edge, which requires building the Graph by hand since
+// NewFromStruct enforces [MinCost, MaxCost]) and returns an error describing the cycle if the
+// graph has a negative cycle. Each pair gets exactly one shortest path. With negative costs a
+// Distance of -1 may be a real distance; use CanReach to test reachability. Node weights
+// (graph.GraphJSON.NodeWeight) are folded into the cost of each node's out-edges, and the
+// source's own weight is taken off again. The per-source searches run concurrently on up to
+// GOMAXPROCS goroutines.
+func RunJohnson(g *graph.Graph) (*AllPairsResult, error) {
+	if err := g.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid graph: %w", err)
+	}
+	adj := g.CostMatrix()
+	N := len(adj)
+	nw := nodeWeightVector(g)
+	// With node weights folded in, a path from s costs nw[s] more than it should; an edge
+	// exists wherever adj has one, even if its folded cost is 0.
+	folded := func(u, v int) (int, bool) {
+		w := adj[u][v]
+		if w != 0 && nw != nil {
+			return w + nw[u], true
+		}
+		return w, w != 0
+	}
+	h, err := bellmanFordPotentials(g, folded)
+	if err != nil {
+		return nil, err
+	}
+	reweighted := func(u, v int) (int, bool) {
+		w, ok := folded(u, v)
+		return w + h[u] - h[v], ok
+	}
+	cost := func(u, v int) int { return adj[u][v] }
+	skip := nonTransitMask(g)
+	dist := make([][]int, N)
+	pred := make([][][]int, N)
+	results := make([]PairResult, N*N)
+	// Each source owns row s of dist and pred and its N results, so no locking is needed.
+	forEachSource(N, func(s int) {
+		d, parent := dijkstraTree(N, s, reweighted, skip)
+		for v := 0; v < N; v++ {
+			if d[v] != Inf {
+				d[v] = d[v] - h[s] + h[v]
+				if nw != nil && v != s {
+					d[v] -= nw[s]
+				}
+			}
+		}
+		dist[s] = d
+		pred[s] = predRow(N, s, d, cost, skip, nw)
+		for v := 0; v < N; v++ {
+			var paths []PathDist
+			if d[v] != Inf {
+				paths = []PathDist{{Path: treePath(g, parent, s, v), Distance: d[v]}}
+			}
+			results[s*N+v] = newPairResult(g, s, v, d[v], paths)
+		}
+	})
+	return &AllPairsResult{Results: results, g: g, dist: dist, pred: pred, limits: g.Limits}, nil
+}
+
+// forEachSource calls fn(s) for every s in [0, n) from a pool of at most GOMAXPROCS goroutines
+// and returns once all calls are done.
+func forEachSource(n int, fn func(s int)) {
+	workers := min(runtime.GOMAXPROCS(0), n)
+	sources := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for s := range sources {
+				fn(s)
+			}
+		}()
+	}
+	for s := 0; s < n; s++ {
+		sources <- s
+	}
+	close(sources)
+	wg.Wait()
+}
+
+// bellmanFordPotentials runs Bellman-Ford over the edges of g costed by edge from a virtual
+// source joined to every node by a zero-cost edge and returns the resulting potentials, or an
+// error naming a negative cycle.
+func bellmanFordPotentials(g *graph.Graph, edge func(u, v int) (int, bool)) ([]int, error) {
+	N := g.NumNodes()
+	h := make([]int, N) // virtual source reaches everything at cost 0
+	parent := make([]int, N)
+	for i := range parent {
+		parent[i] = -1
+	}
+	last := -1
+	if N == 0 {
+		return h, nil
+	}
+	for iter := 0; iter < N; iter++ {
+		last = -1
+		for u := 0; u < N; u++ {
+			for v := 0; v < N; v++ {
+				if w, ok := edge(u, v); ok && h[u]+w < h[v] {
+					h[v] = h[u] + w
+					parent[v] = u
+					last = v
+				}
+			}
+		}
+		if last < 0 {
+			return h, nil
+		}
+	}
+	// Still relaxing after N rounds: walk back N steps to land on the cycle, then collect it.
+	v := last
+	for i := 0; i < N; i++ {
+		v = parent[v]
+	}
+	cycle := []string{g.Name(v)}
+	for u := parent[v]; u != v; u = parent[u] {
+		cycle = append(cycle, g.Name(u))
+	}
+	cycle = append(cycle, g.Name(v))
+	for i, j := 0, len(cycle)-1; i < j; i, j = i+1, j-1 {
+		cycle[i], cycle[j] = cycle[j], cycle[i]
+	}
+	return nil, fmt.Errorf("negative cycle: %s", strings.Join(cycle, " -> "))
+}
+
+// treePath returns the node names from s to v following parent links back from v.
+func treePath(g *graph.Graph, parent []int, s, v int) []string {
+	var rev []string
+	for u := v; u != -1; u = parent[u] {
+		rev = append(rev, g.Name(u))
+		if u == s {
+			break
+		}
+	}
+	path := make([]string, len(rev))
+	for i := range rev {
+		path[i] = rev[len(rev)-1-i]
+	}
+	return path
+}
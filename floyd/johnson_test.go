@@ -0,0 +1,153 @@
+package floyd
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/jursonmo/pathroute/graph"
+)
+
+// handBuiltGraph builds a Graph directly from a cost matrix, bypassing NewFromStruct's cost range
+// so negative costs can be used.
+func handBuiltGraph(nodes []string, adj [][]int) *graph.Graph {
+	nameToIndex := make(map[string]int)
+	for i, n := range nodes {
+		nameToIndex[n] = i
+	}
+	return &graph.Graph{Nodes: nodes, NameToIndex: nameToIndex, AdjMatrix: adj}
+}
+
+func TestRunJohnson_AgreesWithFloyd(t *testing.T) {
+	for _, g := range []*graph.Graph{weightedGraph(t), fanOutGraph(t)} {
+		j, err := RunJohnson(g)
+		if err != nil {
+			t.Fatal(err)
+		}
+		f := RunFloyd(g)
+		if !reflect.DeepEqual(j.dist, f.dist) || !reflect.DeepEqual(j.pred, f.pred) {
+			t.Errorf("Johnson and Floyd disagree on %v", g.Nodes)
+		}
+		for k := range f.Results {
+			if j.Results[k].Distance != f.Results[k].Distance {
+				t.Errorf("%s->%s: Johnson %d, Floyd %d", f.Results[k].From, f.Results[k].To,
+					j.Results[k].Distance, f.Results[k].Distance)
+			}
+		}
+	}
+}
+
+func TestRunJohnson_NodeWeights(t *testing.T) {
+	// A->B->D costs 20 but B charges 10 to pass through; A->C->D costs 24.
+	g, err := graph.NewFromStruct(&graph.GraphJSON{
+		Edges: []graph.Edge{
+			{From: "A", To: "B", Cost: 10}, {From: "B", To: "D", Cost: 10},
+			{From: "A", To: "C", Cost: 12}, {From: "C", To: "D", Cost: 12},
+			{From: "D", To: "A", Cost: 5},
+		},
+		NodeWeight: map[string]int{"A": 7, "B": 10, "C": 1},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	j, err := RunJohnson(g)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := RunFloyd(g)
+	if !reflect.DeepEqual(j.dist, f.dist) || !reflect.DeepEqual(j.pred, f.pred) {
+		t.Errorf("Johnson and Floyd disagree:\n%v\n%v", j.dist, f.dist)
+	}
+	if p := findResult(j, "A", "D").Paths[0]; JoinPathKey(p.Path) != "A|C|D" || p.Distance != 25 {
+		t.Errorf("A->D = %v, want A|C|D at 25", p)
+	}
+}
+
+func TestRunJohnson_RandomGraphMatchesFloyd(t *testing.T) {
+	g := graph.RandomGraph(60, 350, 100, 1)
+	j, err := RunJohnson(g)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := floydDistances(g); !reflect.DeepEqual(j.dist, want) {
+		t.Error("parallel Johnson distances differ from Floyd")
+	}
+	for k, pr := range j.Results {
+		if i, v := k/g.NumNodes(), k%g.NumNodes(); pr.From != g.Name(i) || pr.To != g.Name(v) {
+			t.Fatalf("result %d is %s->%s, want %s->%s", k, pr.From, pr.To, g.Name(i), g.Name(v))
+		}
+	}
+}
+
+func TestRunJohnson_NegativeEdge(t *testing.T) {
+	// A->B 4, A->C 2, C->B -3 (negative), B->D 1: A->B is 2+(-3) = -1, A->D = 0.
+	g := handBuiltGraph([]string{"A", "B", "C", "D"}, [][]int{
+		{0, 4, 2, 0},
+		{0, 0, 0, 1},
+		{0, -3, 0, 0},
+		{0, 0, 0, 0},
+	})
+	r, err := RunJohnson(g)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]int{"AB": -1, "AC": 2, "AD": 0, "CB": -3, "CD": -2}
+	for pair, d := range want {
+		pr := findResult(r, pair[:1], pair[1:])
+		if pr.Distance != d {
+			t.Errorf("%s: got %d, want %d", pair, pr.Distance, d)
+		}
+	}
+	if p := findResult(r, "A", "D").Paths[0].Path; strings.Join(p, "") != "ACBD" {
+		t.Errorf("A->D path: got %v, want [A C B D]", p)
+	}
+	if ok, _ := r.CanReach("D", "A"); ok {
+		t.Error("D->A should be unreachable")
+	}
+}
+
+func TestRunJohnson_NegativeDistanceQueries(t *testing.T) {
+	// As in TestRunJohnson_NegativeEdge: A->B is -1 via C and A->D is 0 via C and B.
+	g := handBuiltGraph([]string{"A", "B", "C", "D"}, [][]int{
+		{0, 4, 2, 0},
+		{0, 0, 0, 1},
+		{0, -3, 0, 0},
+		{0, 0, 0, 0},
+	})
+	r, err := RunJohnson(g)
+	if err != nil {
+		t.Fatal(err)
+	}
+	q, err := r.Query([][2]string{{"A", "B"}, {"C", "D"}, {"D", "A"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if q[0].Distance != -1 || strings.Join(q[0].Path, "") != "ACB" {
+		t.Errorf("Query A->B = %v, want [A C B] at -1", q[0])
+	}
+	if q[1].Distance != -2 || q[2].Distance != Unreachable || q[2].Path != nil {
+		t.Errorf("Query C->D, D->A = %v, %v", q[1], q[2])
+	}
+	want := map[string]float64{"A": 0, "B": 2, "C": 2, "D": 0}
+	if got := r.NodeBetweenness(); !reflect.DeepEqual(got, want) {
+		t.Errorf("NodeBetweenness = %v, want %v", got, want)
+	}
+	results := append([]PairResult(nil), r.Results...)
+	SortResults(results, SortByDistance)
+	if pr := results[0]; pr.From != "C" || pr.To != "B" || pr.Distance != -3 {
+		t.Errorf("first sorted pair = %s->%s (%d), want C->B (-3)", pr.From, pr.To, pr.Distance)
+	}
+}
+
+func TestRunJohnson_NegativeCycle(t *testing.T) {
+	// A->B 1, B->C -2, C->A -1: cycle cost -2.
+	g := handBuiltGraph([]string{"A", "B", "C"}, [][]int{
+		{0, 1, 0},
+		{0, 0, -2},
+		{-1, 0, 0},
+	})
+	_, err := RunJohnson(g)
+	if err == nil || !strings.Contains(err.Error(), "negative cycle") {
+		t.Fatalf("expected negative cycle error, got %v", err)
+	}
+}
@@ -0,0 +1,64 @@
+package floyd
+
+import (
+	"fmt"
+
+	"github.com/jursonmo/pathroute/graph"
+)
+
+// MaxFlow returns the maximum flow from source to sink, treating g.Capacity of every edge as its
+// capacity (edges without an explicit capacity use their cost). It runs Edmonds-Karp: BFS finds
+// the shortest augmenting path in the residual graph until none remains. It errors if a name is
+// unknown or source == sink; an unreachable sink has flow 0.
+func MaxFlow(g *graph.Graph, source, sink string) (int, error) {
+	s, ok := g.Index(source)
+	if !ok {
+		return 0, fmt.Errorf("unknown node %q", source)
+	}
+	t, ok := g.Index(sink)
+	if !ok {
+		return 0, fmt.Errorf("unknown node %q", sink)
+	}
+	if s == t {
+		return 0, fmt.Errorf("source and sink must differ: %q", source)
+	}
+	N := g.NumNodes()
+	residual := make([][]int, N)
+	for i := range residual {
+		residual[i] = make([]int, N)
+		for j := 0; j < N; j++ {
+			residual[i][j] = g.Capacity(i, j)
+		}
+	}
+	flow := 0
+	parent := make([]int, N)
+	for {
+		for i := range parent {
+			parent[i] = -1
+		}
+		parent[s] = s
+		queue := []int{s}
+		for len(queue) > 0 && parent[t] < 0 {
+			u := queue[0]
+			queue = queue[1:]
+			for v := 0; v < N; v++ {
+				if parent[v] < 0 && residual[u][v] > 0 {
+					parent[v] = u
+					queue = append(queue, v)
+				}
+			}
+		}
+		if parent[t] < 0 {
+			return flow, nil
+		}
+		aug := Inf
+		for v := t; v != s; v = parent[v] {
+			aug = min(aug, residual[parent[v]][v])
+		}
+		for v := t; v != s; v = parent[v] {
+			residual[parent[v]][v] -= aug
+			residual[v][parent[v]] += aug
+		}
+		flow += aug
+	}
+}
@@ -0,0 +1,43 @@
+package floyd
+
+import (
+	"testing"
+
+	"github.com/jursonmo/pathroute/graph"
+)
+
+func TestMaxFlow(t *testing.T) {
+	// Classic CLRS flow network; its maximum s->t flow is 23.
+	g, err := graph.NewFromStruct(&graph.GraphJSON{
+		Edges: []graph.Edge{
+			{From: "s", To: "v1", Cost: 16},
+			{From: "s", To: "v2", Cost: 13},
+			{From: "v1", To: "v3", Cost: 12},
+			{From: "v2", To: "v1", Cost: 4},
+			{From: "v2", To: "v4", Cost: 14},
+			{From: "v3", To: "v2", Cost: 9},
+			{From: "v3", To: "t", Cost: 20},
+			{From: "v4", To: "v3", Cost: 7},
+			{From: "v4", To: "t", Cost: 4},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	flow, err := MaxFlow(g, "s", "t")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if flow != 23 {
+		t.Errorf("max flow s->t = %d, want 23", flow)
+	}
+	if flow, err := MaxFlow(g, "t", "s"); err != nil || flow != 0 {
+		t.Errorf("max flow t->s = %d, %v; want 0, nil", flow, err)
+	}
+	if _, err := MaxFlow(g, "s", "s"); err == nil {
+		t.Error("expected error for source == sink")
+	}
+	if _, err := MaxFlow(g, "s", "x"); err == nil {
+		t.Error("expected error for unknown sink")
+	}
+}
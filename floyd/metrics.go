@@ -0,0 +1,75 @@
+package floyd
+
+import "github.com/jursonmo/pathroute/graph"
+
+// RunFloydBy is RunFloyd minimizing the named metric (an Edge.Weights key) instead of the cost;
+// "" and graph.CostMetric keep the cost. Edges that do not carry the metric are not used. Every
+// path in the result also reports, in PathDist.Metrics, the accumulated value of each other
+// metric along it (including graph.CostMetric when optimizing for something else); edges that
+// do not carry a metric contribute 0 to it. Distances are in units of metric.
+func RunFloydBy(g *graph.Graph, metric string) (*AllPairsResult, error) {
+	mg, err := g.MetricGraph(metric)
+	if err != nil {
+		return nil, err
+	}
+	r, err := RunFloydWithOptions(mg, nil)
+	if err != nil {
+		return nil, err
+	}
+	if metric == "" {
+		metric = graph.CostMetric
+	}
+	var others []string
+	if metric != graph.CostMetric {
+		others = append(others, graph.CostMetric)
+	}
+	for _, name := range g.MetricNames() {
+		if name != metric {
+			others = append(others, name)
+		}
+	}
+	r.annotateMetrics(g, others)
+	return r, nil
+}
+
+// annotateMetrics sets PathDist.Metrics of every path in r to the values of metrics summed
+// along it in g; it does nothing if metrics is empty.
+func (r *AllPairsResult) annotateMetrics(g *graph.Graph, metrics []string) {
+	if len(metrics) == 0 {
+		return
+	}
+	for k := range r.Results {
+		for p := range r.Results[k].Paths {
+			pd := &r.Results[k].Paths[p]
+			if pd.Metrics == nil {
+				pd.Metrics = make(map[string]int, len(metrics))
+			}
+			for _, name := range metrics {
+				pd.Metrics[name] = pathMetric(g, name, pd.Path)
+			}
+		}
+	}
+}
+
+// annotateRawCost sets PathDist.Metrics[graph.CostMetric] of each of paths to its cost without
+// the hop penalty r.g's edges carry (see Options.HopPenalty).
+func (r *AllPairsResult) annotateRawCost(paths []PathDist) {
+	for p := range paths {
+		pd := &paths[p]
+		if pd.Metrics == nil {
+			pd.Metrics = make(map[string]int, 1)
+		}
+		pd.Metrics[graph.CostMetric] = pathMetric(r.g, graph.CostMetric, pd.Path) - r.hopPenalty*(len(pd.Path)-1)
+	}
+}
+
+// pathMetric sums metric over the edges of path.
+func pathMetric(g *graph.Graph, metric string, path []string) int {
+	total := 0
+	for k := 1; k < len(path); k++ {
+		i, _ := g.Index(path[k-1])
+		j, _ := g.Index(path[k])
+		total += g.MetricValue(metric, i, j)
+	}
+	return total
+}
@@ -0,0 +1,89 @@
+package floyd
+
+import (
+	"testing"
+
+	"github.com/jursonmo/pathroute/graph"
+)
+
+// latencyGraph: A->B->D is cheap but slow, A->C->D is expensive but fast.
+func latencyGraph(tb testing.TB) *graph.Graph {
+	tb.Helper()
+	g, err := graph.NewFromStruct(&graph.GraphJSON{
+		Nodes: []string{"A", "B", "C", "D"},
+		Edges: []graph.Edge{
+			{From: "A", To: "B", Cost: 10, Weights: map[string]int{"latency": 100}},
+			{From: "B", To: "D", Cost: 10, Weights: map[string]int{"latency": 100}},
+			{From: "A", To: "C", Cost: 50, Weights: map[string]int{"latency": 5}},
+			{From: "C", To: "D", Cost: 50, Weights: map[string]int{"latency": 5}},
+		},
+	})
+	if err != nil {
+		tb.Fatal(err)
+	}
+	return g
+}
+
+func TestRunFloydBy(t *testing.T) {
+	g := latencyGraph(t)
+
+	byCost, err := RunFloydBy(g, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	pr := findResult(byCost, "A", "D")
+	if pr.Distance != 20 || JoinPathKey(pr.Paths[0].Path) != "A|B|D" {
+		t.Fatalf("by cost: got %d %v, want 20 [A B D]", pr.Distance, pr.Paths[0].Path)
+	}
+	if got := pr.Paths[0].Metrics["latency"]; got != 200 {
+		t.Errorf("by cost: latency = %d, want 200", got)
+	}
+
+	byLatency, err := RunFloydBy(g, "latency")
+	if err != nil {
+		t.Fatal(err)
+	}
+	pr = findResult(byLatency, "A", "D")
+	if pr.Distance != 10 || JoinPathKey(pr.Paths[0].Path) != "A|C|D" {
+		t.Fatalf("by latency: got %d %v, want 10 [A C D]", pr.Distance, pr.Paths[0].Path)
+	}
+	if got := pr.Paths[0].Metrics[graph.CostMetric]; got != 100 {
+		t.Errorf("by latency: cost = %d, want 100", got)
+	}
+	if _, ok := pr.Paths[0].Metrics["latency"]; ok {
+		t.Error("by latency: optimized metric should not be repeated in Metrics")
+	}
+
+	if _, err := RunFloydBy(g, "jitter"); err == nil {
+		t.Error("unknown metric: want error")
+	}
+}
+
+func TestRunFloydBy_AfterEdgeMutation(t *testing.T) {
+	g := latencyGraph(t)
+	if err := g.RemoveEdge("A", "C"); err != nil {
+		t.Fatal(err)
+	}
+	r, err := RunFloydBy(g, "latency")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pr := findResult(r, "A", "D"); pr.Distance != 200 || JoinPathKey(pr.Paths[0].Path) != "A|B|D" {
+		t.Errorf("after removing A->C: got %d %v, want 200 [A B D]", pr.Distance, pr.Paths[0].Path)
+	}
+	if err := g.AddEdge("A", "D", 5); err == nil {
+		t.Error("expected error adding an edge without its latency")
+	}
+	if err := g.AddEdgeWeights("A", "D", 5, map[string]int{"latency": 20, "jitter": 1}); err == nil {
+		t.Error("expected error for a metric the graph does not carry")
+	}
+	if err := g.AddEdgeWeights("A", "D", 5, map[string]int{"latency": 20}); err != nil {
+		t.Fatal(err)
+	}
+	if r, err = RunFloydBy(g, "latency"); err != nil {
+		t.Fatal(err)
+	}
+	if pr := findResult(r, "A", "D"); pr.Distance != 20 || pr.Paths[0].Metrics[graph.CostMetric] != 5 {
+		t.Errorf("after adding A->D: got %d %v, want latency 20 and cost 5", pr.Distance, pr.Paths[0])
+	}
+}
@@ -0,0 +1,811 @@
+package floyd
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/jursonmo/pathroute/graph"
+)
+
+// PairsWithin returns the results of all non-self pairs whose shortest distance is
+// finite and at most maxDist, in Results order.
+func (r *AllPairsResult) PairsWithin(maxDist int) []PairResult {
+	N := len(r.dist)
+	var out []PairResult
+	for i := 0; i < N; i++ {
+		for j := 0; j < N; j++ {
+			if i == j || r.dist[i][j] == Inf || r.dist[i][j] > maxDist {
+				continue
+			}
+			out = append(out, *r.result(i, j))
+		}
+	}
+	return out
+}
+
+// TopByDistance returns the results of the n non-self reachable pairs with the longest shortest
+// distance, longest first; ties are ordered by from and then to name.
+func (r *AllPairsResult) TopByDistance(n int) []PairResult {
+	return r.topPairs(n, func(pr *PairResult) int { return pr.Distance })
+}
+
+// TopByHops returns the results of the n non-self reachable pairs whose first shortest path has
+// the most hops, most first; ties are ordered by from and then to name. Pairs without paths (see
+// Options.DistancesOnly) count as 0 hops.
+func (r *AllPairsResult) TopByHops(n int) []PairResult {
+	return r.topPairs(n, func(pr *PairResult) int {
+		if len(pr.Paths) == 0 {
+			return 0
+		}
+		return len(pr.Paths[0].Path) - 1
+	})
+}
+
+// topPairs returns the n non-self reachable results with the largest key, breaking ties by name.
+func (r *AllPairsResult) topPairs(n int, key func(*PairResult) int) []PairResult {
+	if n <= 0 {
+		return nil
+	}
+	out := r.PairsWithin(Inf - 1)
+	keys := make(map[[2]string]int, len(out))
+	for k := range out {
+		keys[[2]string{out[k].From, out[k].To}] = key(&out[k])
+	}
+	sort.Slice(out, func(a, b int) bool {
+		ka, kb := keys[[2]string{out[a].From, out[a].To}], keys[[2]string{out[b].From, out[b].To}]
+		if ka != kb {
+			return ka > kb
+		}
+		if out[a].From != out[b].From {
+			return out[a].From < out[b].From
+		}
+		return out[a].To < out[b].To
+	})
+	return out[:min(n, len(out))]
+}
+
+// ShortestPathCount returns the number of distinct shortest paths from -> to, counted by dynamic
+// programming over the predecessor DAG rather than by enumeration, so it is not limited by
+// MaxShortestPaths. It returns 0 if either name is unknown or to is unreachable, and 1 for from == to.
+func (r *AllPairsResult) ShortestPathCount(from, to string) int {
+	i, ok := r.g.Index(from)
+	if !ok {
+		return 0
+	}
+	j, ok := r.g.Index(to)
+	if !ok || r.dist[i][j] == Inf {
+		return 0
+	}
+	memo := make(map[int]int)
+	return r.countPaths(i, j, memo)
+}
+
+// countPaths counts shortest i->j paths: one per predecessor path plus the direct edge when it is
+// itself shortest (pred excludes i, see predRow).
+func (r *AllPairsResult) countPaths(i, j int, memo map[int]int) int {
+	if i == j {
+		return 1
+	}
+	if c, ok := memo[j]; ok {
+		return c
+	}
+	c := 0
+	if w := r.g.Cost(i, j); w > 0 && w == r.dist[i][j] {
+		c++
+	}
+	for _, m := range r.predecessors()[i][j] {
+		c += r.countPaths(i, m, memo)
+	}
+	memo[j] = c
+	return c
+}
+
+// CommonPrefix returns the longest shared prefix of the first shortest paths from from to each
+// of dests, i.e. the hops all destinations share before their paths diverge. It is just [from]
+// when the paths diverge immediately. Unknown or unreachable destinations are ignored. It errors
+// if from is unknown or the result was computed with Options.DistancesOnly, which keeps no paths.
+func (r *AllPairsResult) CommonPrefix(from string, dests []string) ([]string, error) {
+	if r.distancesOnly {
+		return nil, fmt.Errorf("common prefix needs paths, which Options.DistancesOnly omits")
+	}
+	i, ok := r.g.Index(from)
+	if !ok {
+		return nil, fmt.Errorf("unknown node %q", from)
+	}
+	var prefix []string
+	for _, d := range dests {
+		j, ok := r.g.Index(d)
+		if !ok {
+			continue
+		}
+		pr := *r.result(i, j)
+		if len(pr.Paths) == 0 {
+			continue
+		}
+		path := pr.Paths[0].Path
+		if prefix == nil {
+			prefix = append([]string(nil), path...)
+			continue
+		}
+		n := 0
+		for n < len(prefix) && n < len(path) && prefix[n] == path[n] {
+			n++
+		}
+		prefix = prefix[:n]
+	}
+	if prefix == nil {
+		return []string{from}, nil
+	}
+	return prefix, nil
+}
+
+// indices resolves from and to to node indices, returning an error naming the unknown node.
+func (r *AllPairsResult) indices(from, to string) (int, int, error) {
+	i, ok := r.g.Index(from)
+	if !ok {
+		return 0, 0, fmt.Errorf("unknown node %q", from)
+	}
+	j, ok := r.g.Index(to)
+	if !ok {
+		return 0, 0, fmt.Errorf("unknown node %q", to)
+	}
+	return i, j, nil
+}
+
+// ReachabilityMatrix returns m with m[i][j] true iff node j is reachable from node i (finite
+// distance), indexed like the graph's nodes. Every node reaches itself.
+func (r *AllPairsResult) ReachabilityMatrix() [][]bool {
+	N := len(r.dist)
+	m := make([][]bool, N)
+	for i := 0; i < N; i++ {
+		m[i] = make([]bool, N)
+		for j := 0; j < N; j++ {
+			m[i][j] = r.dist[i][j] != Inf
+		}
+	}
+	return m
+}
+
+// CanReach reports whether to is reachable from from.
+func (r *AllPairsResult) CanReach(from, to string) (bool, error) {
+	i, j, err := r.indices(from, to)
+	if err != nil {
+		return false, err
+	}
+	return r.dist[i][j] != Inf, nil
+}
+
+// SecondShortestDistance returns the smallest simple-path distance from -> to that is strictly
+// greater than the shortest distance; equal-cost alternatives are skipped. ok is false if a name is
+// unknown, the pair is unreachable, or no costlier simple path exists.
+func (r *AllPairsResult) SecondShortestDistance(from, to string) (int, bool) {
+	i, j, err := r.indices(from, to)
+	if err != nil || r.dist[i][j] == Inf {
+		return 0, false
+	}
+	shortest := r.dist[i][j]
+	second, ok := 0, false
+	walkSimplePaths(r.g, i, j, r.g.Cost, func(p PathDist) bool {
+		if p.Distance > shortest {
+			second, ok = p.Distance, true
+			return false
+		}
+		return true
+	})
+	return second, ok
+}
+
+// ViaNeighborStretch returns the distance of the best via-neighbor path from -> to divided by the
+// shortest distance; values near 1.0 mean a cheap backup exists. ok is false if a name is unknown
+// or the pair has no via-neighbor path, including before FillViaNeighborPaths has run.
+func (r *AllPairsResult) ViaNeighborStretch(from, to string) (float64, bool) {
+	i, j, err := r.indices(from, to)
+	if err != nil || i == j {
+		return 0, false
+	}
+	via := r.result(i, j).ViaNeighborPaths
+	if len(via) == 0 {
+		return 0, false
+	}
+	return float64(via[0].Distance) / float64(r.dist[i][j]), true
+}
+
+// ShortestPathTree returns, for every node reachable from from, its parent on a shortest path
+// from from; from itself maps to "". When several predecessors tie, the one with the lowest node
+// index is chosen, so the tree is deterministic.
+func (r *AllPairsResult) ShortestPathTree(from string) (map[string]string, error) {
+	i, ok := r.g.Index(from)
+	if !ok {
+		return nil, fmt.Errorf("unknown node %q", from)
+	}
+	tree := map[string]string{from: ""}
+	for j := 0; j < len(r.dist); j++ {
+		if j == i || r.dist[i][j] == Inf {
+			continue
+		}
+		tree[r.g.Name(j)] = r.g.Name(r.treeParent(i, j))
+	}
+	return tree, nil
+}
+
+// treeParent returns the lowest-index predecessor of j on a shortest i->j path, counting the
+// source i itself when the direct edge is shortest. j must be reachable from i and differ from it.
+func (r *AllPairsResult) treeParent(i, j int) int {
+	p := -1
+	if w := r.g.Cost(i, j); w > 0 && w == r.dist[i][j] {
+		p = i
+	}
+	for _, m := range r.predecessors()[i][j] {
+		if p < 0 || m < p {
+			p = m
+		}
+	}
+	return p
+}
+
+// AsymmetricPairs returns, once per unordered pair {i, j}, the i->j result (i < j in node order)
+// for pairs reachable in both directions whose distances differ by more than ratioThreshold,
+// i.e. max(d_ij, d_ji) / min(d_ij, d_ji) > ratioThreshold.
+func (r *AllPairsResult) AsymmetricPairs(ratioThreshold float64) []PairResult {
+	N := len(r.dist)
+	var out []PairResult
+	for i := 0; i < N; i++ {
+		for j := i + 1; j < N; j++ {
+			a, b := r.dist[i][j], r.dist[j][i]
+			if a == Inf || b == Inf {
+				continue
+			}
+			if float64(max(a, b))/float64(min(a, b)) > ratioThreshold {
+				out = append(out, *r.result(i, j))
+			}
+		}
+	}
+	return out
+}
+
+// Query answers a batch of (from, to) lookups. It indexes Results once and returns, for each pair
+// in order, the first shortest path with its distance; unreachable pairs get a PathDist with
+// Distance -1 and no path, and results computed with Options.DistancesOnly give the distance
+// without a path. An unknown node name is an error identifying the offending pair.
+func (r *AllPairsResult) Query(pairs [][2]string) ([]PathDist, error) {
+	index := make(map[[2]string]int, len(r.Results))
+	for k, pr := range r.Results {
+		index[[2]string{pr.From, pr.To}] = k
+	}
+	out := make([]PathDist, len(pairs))
+	for n, p := range pairs {
+		k, ok := index[p]
+		if !ok {
+			return nil, fmt.Errorf("query %d (%s -> %s): unknown node", n, p[0], p[1])
+		}
+		pr := r.Results[k]
+		i, _ := r.g.Index(pr.From)
+		j, _ := r.g.Index(pr.To)
+		switch {
+		case r.dist[i][j] == Inf:
+			out[n] = PathDist{Distance: Unreachable}
+		case len(pr.Paths) == 0:
+			out[n] = PathDist{Distance: pr.Distance}
+		default:
+			out[n] = pr.Paths[0]
+		}
+	}
+	return out, nil
+}
+
+// AverageDistance returns the mean shortest distance over all non-self pairs with a finite
+// distance, and the number of such pairs; it returns (0, 0) if there are none.
+func (r *AllPairsResult) AverageDistance() (float64, int) {
+	N := len(r.dist)
+	sum, count := 0, 0
+	for i := 0; i < N; i++ {
+		for j := 0; j < N; j++ {
+			if i == j || r.dist[i][j] == Inf {
+				continue
+			}
+			sum += r.dist[i][j]
+			count++
+		}
+	}
+	if count == 0 {
+		return 0, 0
+	}
+	return float64(sum) / float64(count), count
+}
+
+// NodeScore is a node's closeness centrality, as returned by TopCentralNodes.
+type NodeScore struct {
+	Name      string  `json:"name"`
+	Closeness float64 `json:"closeness"`
+}
+
+// TopCentralNodes returns the n nodes with the highest closeness centrality, the inverse of the
+// average distance to the nodes they reach, sorted by descending closeness and then by name.
+// Nodes that reach nothing score 0. All nodes are returned if n exceeds their number.
+func (r *AllPairsResult) TopCentralNodes(n int) []NodeScore {
+	if n <= 0 {
+		return nil
+	}
+	N := len(r.dist)
+	scores := make([]NodeScore, N)
+	for i := 0; i < N; i++ {
+		sum, count := 0, 0
+		for j := 0; j < N; j++ {
+			if i != j && r.dist[i][j] != Inf {
+				sum += r.dist[i][j]
+				count++
+			}
+		}
+		scores[i].Name = r.g.Name(i)
+		if count > 0 && sum > 0 {
+			scores[i].Closeness = float64(count) / float64(sum)
+		}
+	}
+	sort.Slice(scores, func(a, b int) bool {
+		if scores[a].Closeness != scores[b].Closeness {
+			return scores[a].Closeness > scores[b].Closeness
+		}
+		return scores[a].Name < scores[b].Name
+	})
+	return scores[:min(n, N)]
+}
+
+// PairsBrokenByRemoving returns the pairs (in Results order) that are reachable now but would
+// become unreachable if node failed, found by recomputing distances on g without node. Pairs
+// with node as an endpoint are not reported.
+func (r *AllPairsResult) PairsBrokenByRemoving(node string) ([][2]string, error) {
+	x, ok := r.g.Index(node)
+	if !ok {
+		return nil, fmt.Errorf("unknown node %q", node)
+	}
+	sub, oldToNew := r.g.CopyWithoutNode(x)
+	subDist, _ := floydWarshall(sub)
+	N := len(r.dist)
+	var out [][2]string
+	for i := 0; i < N; i++ {
+		for j := 0; j < N; j++ {
+			if i == j || i == x || j == x || r.dist[i][j] == Inf {
+				continue
+			}
+			if subDist[oldToNew[i]][oldToNew[j]] == Inf {
+				out = append(out, [2]string{r.g.Name(i), r.g.Name(j)})
+			}
+		}
+	}
+	return out, nil
+}
+
+// PairsDependentOnEdge returns the pairs (in Results order) that have no equally short path
+// avoiding edge from -> to: removing the edge would make them unreachable or longer. Distances are
+// recomputed on a clone of g without the edge. It is an error if the edge does not exist.
+func (r *AllPairsResult) PairsDependentOnEdge(from, to string) ([][2]string, error) {
+	u, v, err := r.indices(from, to)
+	if err != nil {
+		return nil, err
+	}
+	if r.g.Cost(u, v) == 0 {
+		return nil, fmt.Errorf("no edge %s -> %s", from, to)
+	}
+	sub := r.g.Clone()
+	if err := sub.RemoveEdge(from, to); err != nil {
+		return nil, err
+	}
+	subDist := floydDistances(sub)
+	N := len(r.dist)
+	var out [][2]string
+	for i := 0; i < N; i++ {
+		for j := 0; j < N; j++ {
+			if i != j && r.dist[i][j] != Inf && subDist[i][j] > r.dist[i][j] {
+				out = append(out, [2]string{r.g.Name(i), r.g.Name(j)})
+			}
+		}
+	}
+	return out, nil
+}
+
+// CutVertices returns, in index order, the nodes whose removal would break some currently
+// reachable pair between two other nodes (see PairsBrokenByRemoving). It recomputes distances
+// once per node, so it costs O(N^4).
+func (r *AllPairsResult) CutVertices() []string {
+	var out []string
+	for i := 0; i < len(r.dist); i++ {
+		name := r.g.Name(i)
+		if broken, _ := r.PairsBrokenByRemoving(name); len(broken) > 0 {
+			out = append(out, name)
+		}
+	}
+	return out
+}
+
+// ShortestToAny returns the first shortest path from from to the nearest of dests (anycast),
+// together with the chosen destination; ties are broken by the lexicographically smallest name.
+// Unreachable destinations are skipped; if none is reachable the PathDist has Distance -1 and
+// the destination is "". An unknown node name is an error.
+func (r *AllPairsResult) ShortestToAny(from string, dests []string) (PathDist, string, error) {
+	pairs := make([][2]string, len(dests))
+	for k, d := range dests {
+		pairs[k] = [2]string{from, d}
+	}
+	return r.nearestPair(pairs, 1)
+}
+
+// ShortestFromAny is the mirror of ShortestToAny: it returns the first shortest path to to from
+// the nearest of sources (e.g. the cheapest ingress), together with the chosen source.
+func (r *AllPairsResult) ShortestFromAny(sources []string, to string) (PathDist, string, error) {
+	pairs := make([][2]string, len(sources))
+	for k, s := range sources {
+		pairs[k] = [2]string{s, to}
+	}
+	return r.nearestPair(pairs, 0)
+}
+
+// nearestPair returns the first shortest path of the pair with the smallest finite distance and
+// that pair's name at position end (0 = from, 1 = to); ties go to the smallest such name.
+func (r *AllPairsResult) nearestPair(pairs [][2]string, end int) (PathDist, string, error) {
+	best, bestName, found := PathDist{Distance: Unreachable}, "", false
+	for _, p := range pairs {
+		i, j, err := r.indices(p[0], p[1])
+		if err != nil {
+			return PathDist{}, "", err
+		}
+		d := r.dist[i][j]
+		if d == Inf {
+			continue
+		}
+		if found && (d > best.Distance || d == best.Distance && p[end] >= bestName) {
+			continue
+		}
+		best, bestName, found = PathDist{Distance: d}, p[end], true
+		if paths := r.result(i, j).Paths; len(paths) > 0 {
+			best = paths[0]
+		}
+	}
+	return best, bestName, nil
+}
+
+// WalkPaths calls fn for each shortest path from -> to, in the order the predecessor lists yield
+// them, until fn returns false. Unlike Paths it is not capped and builds no list, so callers can
+// stop at the first match. Nothing is called if a name is unknown or to is unreachable.
+func (r *AllPairsResult) WalkPaths(from, to string, fn func(PathDist) bool) {
+	i, j, err := r.indices(from, to)
+	if err != nil || r.dist[i][j] == Inf {
+		return
+	}
+	d := r.dist[i][j]
+	if i == j {
+		fn(PathDist{Path: []string{from}, Distance: 0})
+		return
+	}
+	walkPredPaths(r.g, r.dist, r.predecessors(), i, j, []string{r.g.Name(j)}, make(map[string]bool), func(path []string) bool {
+		return fn(PathDist{Path: path, Distance: d})
+	})
+}
+
+// PathsMatching returns up to maxPaths shortest paths from -> to, in WalkPaths order, for which
+// pred returns true. Rejected paths do not count towards maxPaths.
+func (r *AllPairsResult) PathsMatching(from, to string, pred func(path []string) bool, maxPaths int) []PathDist {
+	if maxPaths <= 0 {
+		return nil
+	}
+	var out []PathDist
+	r.WalkPaths(from, to, func(p PathDist) bool {
+		if pred(p.Path) {
+			out = append(out, p)
+		}
+		return len(out) < maxPaths
+	})
+	return out
+}
+
+// MultipathPairs returns, in Results order, the non-self pairs with more than one distinct
+// shortest path (ShortestPathCount > 1), i.e. where equal-cost multipath applies.
+func (r *AllPairsResult) MultipathPairs() [][2]string {
+	N := len(r.dist)
+	var out [][2]string
+	for i := 0; i < N; i++ {
+		memo := make(map[int]int)
+		for j := 0; j < N; j++ {
+			if i == j || r.dist[i][j] == Inf {
+				continue
+			}
+			if r.countPaths(i, j, memo) > 1 {
+				out = append(out, [2]string{r.g.Name(i), r.g.Name(j)})
+			}
+		}
+	}
+	return out
+}
+
+// PathIndices returns the enumerated paths from -> to (PairResult.Paths) as slices of node
+// indices into the graph's Nodes; it is nil for an unreachable pair.
+func (r *AllPairsResult) PathIndices(from, to string) ([][]int, error) {
+	i, j, err := r.indices(from, to)
+	if err != nil {
+		return nil, err
+	}
+	paths := r.result(i, j).Paths
+	if len(paths) == 0 {
+		return nil, nil
+	}
+	out := make([][]int, len(paths))
+	for k, p := range paths {
+		out[k] = make([]int, len(p.Path))
+		for h, name := range p.Path {
+			out[k][h], _ = r.g.Index(name)
+		}
+	}
+	return out, nil
+}
+
+// Predecessors returns, in node order, the nodes that precede to on some shortest path from
+// from: the last hop before to. from itself is included when the direct edge is a shortest
+// path. It is nil for an unreachable pair or from == to.
+func (r *AllPairsResult) Predecessors(from, to string) ([]string, error) {
+	i, j, err := r.indices(from, to)
+	if err != nil {
+		return nil, err
+	}
+	if i == j || r.dist[i][j] == Inf {
+		return nil, nil
+	}
+	isPred := make([]bool, len(r.dist))
+	for _, m := range r.predecessors()[i][j] {
+		isPred[m] = true
+	}
+	if c := r.g.Cost(i, j); c != 0 && c == r.dist[i][j] {
+		isPred[i] = true
+	}
+	var out []string
+	for m, ok := range isPred {
+		if ok {
+			out = append(out, r.g.Name(m))
+		}
+	}
+	return out, nil
+}
+
+// ExplainUnreachable returns a short diagnosis of why to is unreachable from from: from has no
+// outgoing edges, to has no incoming edges, only non-transit nodes connect them, or otherwise how
+// many nodes each side reaches, with no edge leading from one side to the other. It is an error
+// if either name is unknown or the pair is reachable.
+func (r *AllPairsResult) ExplainUnreachable(from, to string) (string, error) {
+	i, j, err := r.indices(from, to)
+	if err != nil {
+		return "", err
+	}
+	if r.dist[i][j] != Inf {
+		return "", fmt.Errorf("%s is reachable from %s", to, from)
+	}
+	N := len(r.dist)
+	cost := r.g.CostMatrix()
+	out, in := 0, 0
+	for k := 0; k < N; k++ {
+		if cost[i][k] != 0 {
+			out++
+		}
+		if cost[k][j] != 0 {
+			in++
+		}
+	}
+	switch {
+	case out == 0:
+		return fmt.Sprintf("%s has no outgoing edges", from), nil
+	case in == 0:
+		return fmt.Sprintf("%s has no incoming edges", to), nil
+	}
+	// reach: other nodes reachable from i; coreach: other nodes that reach j.
+	reach, coreach := 0, 0
+	for k := 0; k < N; k++ {
+		if k != i && r.dist[i][k] != Inf {
+			reach++
+		}
+		if k != j && r.dist[k][j] != Inf {
+			coreach++
+		}
+	}
+	for m := 0; m < N; m++ {
+		if m == i || r.dist[i][m] == Inf || r.g.Transit(m) {
+			continue
+		}
+		for k := 0; k < N; k++ {
+			if cost[m][k] != 0 && r.dist[k][j] != Inf {
+				return fmt.Sprintf("paths from %s to %s would have to pass through non-transit node %s", from, to, r.g.Name(m)), nil
+			}
+		}
+	}
+	msg := fmt.Sprintf("no path from %s to %s: %s reaches %d other node(s) and %d other node(s) reach %s, with no edge from the first group to the second",
+		from, to, from, reach, coreach, to)
+	if r.dist[j][i] != Inf {
+		msg += fmt.Sprintf(" (%s reaches %s, only not the other way)", to, from)
+	}
+	return msg, nil
+}
+
+// Eccentricity returns the largest shortest distance from node to any other node. ok is false if
+// node is unknown or some node is unreachable from it.
+func (r *AllPairsResult) Eccentricity(node string) (int, bool) {
+	i, ok := r.g.Index(node)
+	if !ok {
+		return 0, false
+	}
+	return r.eccentricity(i)
+}
+
+// eccentricity is Eccentricity by node index.
+func (r *AllPairsResult) eccentricity(i int) (int, bool) {
+	e := 0
+	for _, d := range r.dist[i] {
+		if d == Inf {
+			return 0, false
+		}
+		e = max(e, d)
+	}
+	return e, true
+}
+
+// Diameter returns the largest eccentricity over all nodes. ok is false if the graph is empty or
+// some pair is unreachable.
+func (r *AllPairsResult) Diameter() (int, bool) {
+	diam := 0
+	for i := range r.dist {
+		e, ok := r.eccentricity(i)
+		if !ok {
+			return 0, false
+		}
+		diam = max(diam, e)
+	}
+	return diam, len(r.dist) > 0
+}
+
+// Radius returns the smallest eccentricity over all nodes. ok is false if no node reaches every
+// other node.
+func (r *AllPairsResult) Radius() (int, bool) {
+	radius, found := 0, false
+	for i := range r.dist {
+		if e, ok := r.eccentricity(i); ok && (!found || e < radius) {
+			radius, found = e, true
+		}
+	}
+	return radius, found
+}
+
+// UnusedEdges returns, in row-major order, the edges that lie on no shortest path between any
+// pair: i -> j is used if it is the direct shortest path from i to j or i is a predecessor of j
+// on a shortest path from some source. Only From, To and Cost are set.
+func (r *AllPairsResult) UnusedEdges() []graph.Edge {
+	cost := r.g.CostMatrix()
+	used := r.usedEdges(cost)
+	var out []graph.Edge
+	for i := range cost {
+		for j, w := range cost[i] {
+			if w != 0 && !used[i][j] {
+				out = append(out, graph.Edge{From: r.g.Name(i), To: r.g.Name(j), Cost: w})
+			}
+		}
+	}
+	return out
+}
+
+// ShortestPathSubgraph returns a new graph with all of g's nodes but only the edges on some
+// shortest path (the complement of UnusedEdges), keeping their costs, capacities and metrics
+// along with g's metadata, limits, non-transit nodes and attributes. The edges are copied as they
+// are, not revalidated, so negative costs (see RunJohnson) carry over; with Options.HopPenalty
+// they keep their raw costs.
+func (r *AllPairsResult) ShortestPathSubgraph() *graph.Graph {
+	sub := r.g.Clone()
+	used := r.usedEdges(sub.AdjMatrix)
+	if r.hopPenalty > 0 {
+		lim := sub.Limits.Resolved()
+		lim.MinCost -= r.hopPenalty
+		lim.MaxCost -= r.hopPenalty
+		sub.Limits = &lim
+	}
+	for i, row := range sub.AdjMatrix {
+		for j, w := range row {
+			switch {
+			case w == 0:
+			case !used[i][j]:
+				row[j] = 0
+				if sub.CapMatrix != nil {
+					sub.CapMatrix[i][j] = 0
+				}
+				for _, m := range sub.Metrics {
+					m[i][j] = 0
+				}
+			default:
+				row[j] = w - r.hopPenalty
+			}
+		}
+	}
+	return sub
+}
+
+// usedEdges marks the edges i -> j of cost that lie on some shortest path (see UnusedEdges).
+func (r *AllPairsResult) usedEdges(cost [][]int) [][]bool {
+	N := len(r.dist)
+	used := make([][]bool, N)
+	for i := range used {
+		used[i] = make([]bool, N)
+		for j, w := range cost[i] {
+			used[i][j] = w != 0 && w == r.dist[i][j]
+		}
+	}
+	pred := r.predecessors()
+	for s := 0; s < N; s++ {
+		for j := 0; j < N; j++ {
+			for _, m := range pred[s][j] {
+				used[m][j] = true
+			}
+		}
+	}
+	return used
+}
+
+// NodeBetweenness returns the betweenness centrality of every node: summed over all ordered
+// pairs (s, t) with s != t, the fraction of shortest s -> t paths that pass through the node as
+// an intermediate hop. Endpoints do not count, so a node no path passes through scores 0. It uses
+// Brandes' accumulation over each source's shortest-path DAG and is not normalized.
+func (r *AllPairsResult) NodeBetweenness() map[string]float64 {
+	N := len(r.dist)
+	pred := r.predecessors()
+	cost := r.g.CostMatrix()
+	out := make(map[string]float64, N)
+	for v := 0; v < N; v++ {
+		out[r.g.Name(v)] = 0
+	}
+	order := make([]int, 0, N)
+	visited := make([]bool, N)
+	sigma := make([]float64, N)
+	delta := make([]float64, N)
+	for s := 0; s < N; s++ {
+		// dagPreds lists the predecessors of w on shortest s -> w paths, including s itself.
+		dagPreds := func(w int) []int {
+			if c := cost[s][w]; c != 0 && c == r.dist[s][w] {
+				return append([]int{s}, pred[s][w]...)
+			}
+			return pred[s][w]
+		}
+		// order is a topological order of the shortest-path DAG, predecessors first. Sorting by
+		// distance is not one once RunJohnson's negative costs are involved.
+		order = order[:0]
+		for v := range visited {
+			visited[v], sigma[v], delta[v] = false, 0, 0
+		}
+		var visit func(w int)
+		visit = func(w int) {
+			visited[w] = true
+			for _, v := range dagPreds(w) {
+				if !visited[v] {
+					visit(v)
+				}
+			}
+			order = append(order, w)
+		}
+		visit(s)
+		for v := 0; v < N; v++ {
+			if !visited[v] && r.dist[s][v] != Inf {
+				visit(v)
+			}
+		}
+		for _, w := range order {
+			if w == s {
+				sigma[w] = 1
+				continue
+			}
+			for _, v := range dagPreds(w) {
+				sigma[w] += sigma[v]
+			}
+		}
+		for k := len(order) - 1; k >= 0; k-- {
+			w := order[k]
+			if w == s {
+				continue
+			}
+			for _, v := range dagPreds(w) {
+				delta[v] += sigma[v] / sigma[w] * (1 + delta[w])
+			}
+			out[r.g.Name(w)] += delta[w]
+		}
+	}
+	return out
+}
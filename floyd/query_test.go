@@ -0,0 +1,773 @@
+package floyd
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/jursonmo/pathroute/graph"
+)
+
+// weightedGraph is the small weighted graph used by TestFloyd_ThreeNodes.
+func weightedGraph(tb testing.TB) *graph.Graph {
+	tb.Helper()
+	g, err := graph.NewFromStruct(&graph.GraphJSON{
+		Nodes: []string{"A", "B", "C"},
+		Edges: []graph.Edge{
+			{From: "A", To: "B", Cost: 50},
+			{From: "B", To: "A", Cost: 80},
+			{From: "A", To: "C", Cost: 100},
+			{From: "B", To: "C", Cost: 20},
+		},
+	})
+	if err != nil {
+		tb.Fatal(err)
+	}
+	return g
+}
+
+func pairNames(results []PairResult) []string {
+	out := make([]string, 0, len(results))
+	for _, pr := range results {
+		out = append(out, pr.From+pr.To)
+	}
+	return out
+}
+
+func TestPairsWithin(t *testing.T) {
+	r := RunFloyd(weightedGraph(t))
+	// A->B 50, A->C 70, B->A 80, B->C 20; C reaches nothing.
+	cases := []struct {
+		budget int
+		want   []string
+	}{
+		{20, []string{"BC"}},
+		{70, []string{"AB", "AC", "BC"}},
+		{1000, []string{"AB", "AC", "BA", "BC"}},
+		{0, nil},
+	}
+	for _, c := range cases {
+		got := pairNames(r.PairsWithin(c.budget))
+		if len(got) != len(c.want) {
+			t.Errorf("budget %d: got %v, want %v", c.budget, got, c.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != c.want[i] {
+				t.Errorf("budget %d: got %v, want %v", c.budget, got, c.want)
+				break
+			}
+		}
+	}
+}
+
+func TestShortestPathCount(t *testing.T) {
+	r := RunFloyd(fanOutGraph(t))
+	if c := r.ShortestPathCount("A", "E"); c != 3 {
+		t.Errorf("fan-out A->E count: got %d, want 3", c)
+	}
+	if ae := findResult(r, "A", "E"); len(ae.Paths) != 3 {
+		t.Errorf("fan-out A->E paths: got %d", len(ae.Paths))
+	}
+	if c := r.ShortestPathCount("E", "A"); c != 0 {
+		t.Errorf("unreachable E->A count: got %d", c)
+	}
+
+	// Three diamonds in series: 2*2*2 = 8 equal-cost paths A->J.
+	g, _ := graph.NewFromStruct(&graph.GraphJSON{
+		Edges: []graph.Edge{
+			{From: "A", To: "B", Cost: 1}, {From: "A", To: "C", Cost: 1},
+			{From: "B", To: "D", Cost: 1}, {From: "C", To: "D", Cost: 1},
+			{From: "D", To: "E", Cost: 1}, {From: "D", To: "F", Cost: 1},
+			{From: "E", To: "G", Cost: 1}, {From: "F", To: "G", Cost: 1},
+			{From: "G", To: "H", Cost: 1}, {From: "G", To: "I", Cost: 1},
+			{From: "H", To: "J", Cost: 1}, {From: "I", To: "J", Cost: 1},
+		},
+	})
+	r = RunFloyd(g)
+	if c := r.ShortestPathCount("A", "J"); c != 8 {
+		t.Errorf("A->J count: got %d, want 8", c)
+	}
+	if aj := findResult(r, "A", "J"); len(aj.Paths) > MaxShortestPaths {
+		t.Errorf("A->J paths should be capped at %d, got %d", MaxShortestPaths, len(aj.Paths))
+	}
+}
+
+func TestCommonPrefix(t *testing.T) {
+	// A->B->C then fans out to D, E, F; A->X is a separate branch.
+	g, _ := graph.NewFromStruct(&graph.GraphJSON{
+		Edges: []graph.Edge{
+			{From: "A", To: "B", Cost: 1},
+			{From: "B", To: "C", Cost: 1},
+			{From: "C", To: "D", Cost: 1},
+			{From: "C", To: "E", Cost: 1},
+			{From: "C", To: "F", Cost: 1},
+			{From: "A", To: "X", Cost: 1},
+		},
+	})
+	r := RunFloyd(g)
+	got, err := r.CommonPrefix("A", []string{"D", "E", "F"})
+	if err != nil || len(got) != 3 || got[0] != "A" || got[1] != "B" || got[2] != "C" {
+		t.Errorf("common prefix of D,E,F: got %v, %v; want [A B C]", got, err)
+	}
+	if got, err := r.CommonPrefix("A", []string{"D", "X"}); err != nil || len(got) != 1 || got[0] != "A" {
+		t.Errorf("diverging immediately: got %v, %v; want [A]", got, err)
+	}
+	if _, err := r.CommonPrefix("Z", []string{"D"}); err == nil {
+		t.Error("expected error for unknown node")
+	}
+	dOnly, err := RunFloydWithOptions(g, &Options{DistancesOnly: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := dOnly.CommonPrefix("A", []string{"D", "E"}); err == nil {
+		t.Error("expected error for a distances-only result")
+	}
+}
+
+func TestReachability(t *testing.T) {
+	r := RunFloyd(weightedGraph(t))
+	m := r.ReachabilityMatrix()
+	a, c := r.g.NameToIndex["A"], r.g.NameToIndex["C"]
+	if !m[a][c] {
+		t.Error("A should reach C")
+	}
+	if m[c][a] {
+		t.Error("C should not reach A")
+	}
+	if !m[c][c] {
+		t.Error("C should reach itself")
+	}
+	if ok, err := r.CanReach("A", "C"); err != nil || !ok {
+		t.Errorf("CanReach(A, C) = %v, %v", ok, err)
+	}
+	if ok, err := r.CanReach("C", "A"); err != nil || ok {
+		t.Errorf("CanReach(C, A) = %v, %v", ok, err)
+	}
+	if _, err := r.CanReach("A", "X"); err == nil {
+		t.Error("expected error for unknown node")
+	}
+}
+
+func TestSecondShortestDistance(t *testing.T) {
+	r := RunFloyd(weightedGraph(t))
+	if d, ok := r.SecondShortestDistance("A", "C"); !ok || d != 100 {
+		t.Errorf("A->C second-best: got %d %v, want 100", d, ok)
+	}
+	if _, ok := r.SecondShortestDistance("A", "B"); ok {
+		t.Error("A->B has a single simple path, expected no second-best")
+	}
+	// Equal-cost ties do not count as a second distance.
+	if _, ok := RunFloyd(fanOutGraph(t)).SecondShortestDistance("A", "E"); ok {
+		t.Error("fan-out A->E only has equal-cost paths, expected no second-best")
+	}
+}
+
+func TestShortestPathTree(t *testing.T) {
+	g := fanOutGraph(t)
+	r := RunFloyd(g)
+	tree, err := r.ShortestPathTree("A")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tree) != 5 || tree["A"] != "" {
+		t.Fatalf("tree should cover all 5 nodes with A as root: %v", tree)
+	}
+	if tree["E"] != "B" {
+		t.Errorf("E's parent should be the lowest-index tie B, got %q", tree["E"])
+	}
+	for node := range tree {
+		// Walk parents back to A and check the accumulated cost equals the shortest distance.
+		total, cur := 0, node
+		for tree[cur] != "" {
+			p := tree[cur]
+			pi, _ := g.Index(p)
+			ci, _ := g.Index(cur)
+			if g.Cost(pi, ci) == 0 {
+				t.Fatalf("parent edge %s->%s does not exist", p, cur)
+			}
+			total += g.Cost(pi, ci)
+			cur = p
+		}
+		if cur != "A" || total != findResult(r, "A", node).Distance {
+			t.Errorf("path to %s via parents: root %s cost %d", node, cur, total)
+		}
+	}
+	if tree, _ := r.ShortestPathTree("E"); len(tree) != 1 {
+		t.Errorf("E reaches nothing, tree should only hold E: %v", tree)
+	}
+	if _, err := r.ShortestPathTree("X"); err == nil {
+		t.Error("expected error for unknown node")
+	}
+}
+
+func TestAsymmetricPairs(t *testing.T) {
+	r := RunFloyd(weightedGraph(t))
+	// A->B = 50, B->A = 80: ratio 1.6.
+	got := r.AsymmetricPairs(1.5)
+	if len(got) != 1 || got[0].From != "A" || got[0].To != "B" {
+		t.Errorf("threshold 1.5: got %v, want [A->B]", pairNames(got))
+	}
+	if got := r.AsymmetricPairs(2.0); len(got) != 0 {
+		t.Errorf("threshold 2.0: got %v, want none", pairNames(got))
+	}
+}
+
+func TestQuery(t *testing.T) {
+	r := RunFloyd(weightedGraph(t))
+	got, err := r.Query([][2]string{{"A", "C"}, {"C", "A"}, {"B", "A"}, {"A", "A"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantDist := []int{70, -1, 80, 0}
+	for i, w := range wantDist {
+		if got[i].Distance != w {
+			t.Errorf("query %d: distance %d, want %d", i, got[i].Distance, w)
+		}
+	}
+	if len(got[0].Path) != 3 || got[1].Path != nil {
+		t.Errorf("paths not lined up: %v", got)
+	}
+	if _, err := r.Query([][2]string{{"A", "B"}, {"A", "Z"}}); err == nil {
+		t.Error("expected error for unknown node")
+	}
+
+	// Without paths the distances are still answered.
+	r, err = RunFloydWithOptions(weightedGraph(t), &Options{DistancesOnly: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	dOnly, err := r.Query([][2]string{{"A", "C"}, {"C", "A"}, {"B", "A"}, {"A", "A"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, w := range wantDist {
+		if dOnly[i].Distance != w || dOnly[i].Path != nil {
+			t.Errorf("distances only, query %d: got %v, want distance %d without path", i, dOnly[i], w)
+		}
+	}
+}
+
+func TestAverageDistance(t *testing.T) {
+	// A->B 50, A->C 70, B->A 80, B->C 20; C reaches nothing: (50+70+80+20)/4 = 55.
+	avg, n := RunFloyd(weightedGraph(t)).AverageDistance()
+	if avg != 55 || n != 4 {
+		t.Errorf("got (%v, %d), want (55, 4)", avg, n)
+	}
+	g, _ := graph.NewFromStruct(&graph.GraphJSON{Nodes: []string{"A", "B"}})
+	if avg, n := RunFloyd(g).AverageDistance(); avg != 0 || n != 0 {
+		t.Errorf("no edges: got (%v, %d), want (0, 0)", avg, n)
+	}
+}
+
+func TestTopCentralNodes(t *testing.T) {
+	// Star: hub H <-> each leaf at cost 1; leaves reach each other in 2 via H. Z is isolated.
+	gj := &graph.GraphJSON{Nodes: []string{"L1", "L2", "L3", "H", "Z"}}
+	for _, leaf := range []string{"L1", "L2", "L3"} {
+		gj.Edges = append(gj.Edges,
+			graph.Edge{From: "H", To: leaf, Cost: 1},
+			graph.Edge{From: leaf, To: "H", Cost: 1})
+	}
+	g, err := graph.NewFromStruct(gj)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := RunFloyd(g).TopCentralNodes(10)
+	want := []NodeScore{
+		{Name: "H", Closeness: 1},
+		{Name: "L1", Closeness: 0.6},
+		{Name: "L2", Closeness: 0.6},
+		{Name: "L3", Closeness: 0.6},
+		{Name: "Z", Closeness: 0},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("TopCentralNodes(10) = %v, want %v", got, want)
+	}
+	if top := RunFloyd(g).TopCentralNodes(1); len(top) != 1 || top[0].Name != "H" {
+		t.Errorf("TopCentralNodes(1) = %v, want [H]", top)
+	}
+}
+
+func TestPairsBrokenByRemoving(t *testing.T) {
+	// A->B->C and A->D->C give A->C two routes; C->E only goes through C.
+	g, err := graph.NewFromStruct(&graph.GraphJSON{Edges: []graph.Edge{
+		{From: "A", To: "B", Cost: 1}, {From: "B", To: "C", Cost: 1},
+		{From: "A", To: "D", Cost: 5}, {From: "D", To: "C", Cost: 5},
+		{From: "C", To: "E", Cost: 1},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := RunFloyd(g)
+	broken, err := r.PairsBrokenByRemoving("B")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(broken) != 0 {
+		t.Errorf("removing B: got %v, want none (A->D->C remains)", broken)
+	}
+	broken, err = r.PairsBrokenByRemoving("C")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := [][2]string{{"A", "E"}, {"B", "E"}, {"D", "E"}}
+	if !reflect.DeepEqual(broken, want) {
+		t.Errorf("removing C: got %v, want %v", broken, want)
+	}
+	if _, err := r.PairsBrokenByRemoving("X"); err == nil {
+		t.Error("expected error for unknown node")
+	}
+}
+
+func TestCutVertices(t *testing.T) {
+	// Two triangles joined through M: A,B <-> M <-> C,D, all edges in both directions.
+	gj := &graph.GraphJSON{}
+	for _, e := range [][2]string{{"A", "B"}, {"A", "M"}, {"B", "M"}, {"M", "C"}, {"M", "D"}, {"C", "D"}} {
+		gj.Edges = append(gj.Edges,
+			graph.Edge{From: e[0], To: e[1], Cost: 1},
+			graph.Edge{From: e[1], To: e[0], Cost: 1})
+	}
+	g, err := graph.NewFromStruct(gj)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := RunFloyd(g).CutVertices(); !reflect.DeepEqual(got, []string{"M"}) {
+		t.Errorf("CutVertices = %v, want [M]", got)
+	}
+}
+
+func TestShortestToAny(t *testing.T) {
+	// Replicas R1 (cost 30), R2 (cost 10 via M) and R3 (cost 10 direct, ties with R2); R4 is unreachable.
+	g, err := graph.NewFromStruct(&graph.GraphJSON{
+		Nodes: []string{"S", "M", "R1", "R2", "R3", "R4"},
+		Edges: []graph.Edge{
+			{From: "S", To: "R1", Cost: 30},
+			{From: "S", To: "M", Cost: 5}, {From: "M", To: "R2", Cost: 5},
+			{From: "S", To: "R3", Cost: 10},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := RunFloyd(g)
+	p, dest, err := r.ShortestToAny("S", []string{"R4", "R3", "R1", "R2"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dest != "R2" || p.Distance != 10 || JoinPathKey(p.Path) != "S|M|R2" {
+		t.Errorf("got %v to %s, want S|M|R2 (10) to R2", p, dest)
+	}
+	p, dest, err = r.ShortestToAny("S", []string{"R4"})
+	if err != nil || dest != "" || p.Distance != -1 {
+		t.Errorf("unreachable: got %v, %q, %v", p, dest, err)
+	}
+	if _, _, err := r.ShortestToAny("S", []string{"X"}); err == nil {
+		t.Error("expected error for unknown destination")
+	}
+}
+
+func TestShortestFromAny(t *testing.T) {
+	g, err := graph.NewFromStruct(&graph.GraphJSON{Edges: []graph.Edge{
+		{From: "I1", To: "D", Cost: 40},
+		{From: "I2", To: "M", Cost: 10}, {From: "M", To: "D", Cost: 15},
+		{From: "I3", To: "D", Cost: 90},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, src, err := RunFloyd(g).ShortestFromAny([]string{"I1", "I2", "I3"}, "D")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if src != "I2" || p.Distance != 25 || JoinPathKey(p.Path) != "I2|M|D" {
+		t.Errorf("got %v from %s, want I2|M|D (25) from I2", p, src)
+	}
+}
+
+func TestWalkPaths(t *testing.T) {
+	r := RunFloyd(graph.GridGraph(4, 4))
+	total := 0
+	r.WalkPaths("r0c0", "r3c3", func(p PathDist) bool {
+		if p.Distance != 6 || len(p.Path) != 7 {
+			t.Errorf("unexpected path %v", p)
+		}
+		total++
+		return true
+	})
+	if total != 20 { // C(6,3) monotone lattice paths
+		t.Errorf("walked %d paths, want 20", total)
+	}
+	calls := 0
+	r.WalkPaths("r0c0", "r3c3", func(PathDist) bool {
+		calls++
+		return false
+	})
+	if calls != 1 {
+		t.Errorf("fn called %d times after returning false, want 1", calls)
+	}
+}
+
+func TestPathsMatching(t *testing.T) {
+	// Diamond A->{B,C}->D plus A->E->D, all of cost 20.
+	g, err := graph.NewFromStruct(&graph.GraphJSON{Edges: []graph.Edge{
+		{From: "A", To: "B", Cost: 10}, {From: "B", To: "D", Cost: 10},
+		{From: "A", To: "C", Cost: 10}, {From: "C", To: "D", Cost: 10},
+		{From: "A", To: "E", Cost: 10}, {From: "E", To: "D", Cost: 10},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	noC := func(path []string) bool {
+		for _, n := range path {
+			if n == "C" {
+				return false
+			}
+		}
+		return true
+	}
+	got := RunFloyd(g).PathsMatching("A", "D", noC, 10)
+	if len(got) != 2 {
+		t.Fatalf("got %v, want the two paths avoiding C", got)
+	}
+	for _, p := range got {
+		if !noC(p.Path) || p.Distance != 20 {
+			t.Errorf("unexpected path %v", p)
+		}
+	}
+	if got := RunFloyd(g).PathsMatching("A", "D", noC, 1); len(got) != 1 {
+		t.Errorf("maxPaths 1: got %v", got)
+	}
+}
+
+func TestMultipathPairs(t *testing.T) {
+	// Diamond: A->B->D and A->C->D both cost 20.
+	g, err := graph.NewFromStruct(&graph.GraphJSON{Edges: []graph.Edge{
+		{From: "A", To: "B", Cost: 10}, {From: "A", To: "C", Cost: 10},
+		{From: "B", To: "D", Cost: 10}, {From: "C", To: "D", Cost: 10},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := RunFloyd(g).MultipathPairs(), [][2]string{{"A", "D"}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("MultipathPairs = %v, want %v", got, want)
+	}
+}
+
+func TestPathIndices(t *testing.T) {
+	g := graph.GridGraph(3, 3)
+	r := RunFloyd(g)
+	idx, err := r.PathIndices("r0c0", "r2c2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	paths := findResult(r, "r0c0", "r2c2").Paths
+	if len(idx) != len(paths) || len(idx) == 0 {
+		t.Fatalf("got %d index paths for %d paths", len(idx), len(paths))
+	}
+	for k, p := range paths {
+		for h, name := range p.Path {
+			if g.Name(idx[k][h]) != name {
+				t.Errorf("path %d hop %d: index %d is %s, want %s", k, h, idx[k][h], g.Name(idx[k][h]), name)
+			}
+		}
+	}
+	if idx, err := r.PathIndices("r2c2", "r0c0"); err != nil || idx != nil {
+		t.Errorf("unreachable: got %v, %v", idx, err)
+	}
+	if _, err := r.PathIndices("r0c0", "zz"); err == nil {
+		t.Error("expected error for unknown node")
+	}
+}
+
+func TestPredecessors(t *testing.T) {
+	g, _ := graph.NewFromStruct(&graph.GraphJSON{Edges: []graph.Edge{
+		{From: "A", To: "B", Cost: 10},
+		{From: "A", To: "C", Cost: 10},
+		{From: "B", To: "D", Cost: 10},
+		{From: "C", To: "D", Cost: 10},
+	}})
+	r := RunFloyd(g)
+	for _, tc := range []struct {
+		from, to string
+		want     []string
+	}{
+		{"A", "D", []string{"B", "C"}},
+		{"A", "B", []string{"A"}},
+		{"D", "A", nil},
+		{"A", "A", nil},
+	} {
+		got, err := r.Predecessors(tc.from, tc.to)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%s -> %s: got %v, want %v", tc.from, tc.to, got, tc.want)
+		}
+	}
+	if _, err := r.Predecessors("A", "zz"); err == nil {
+		t.Error("expected error for unknown node")
+	}
+}
+
+func TestExplainUnreachable(t *testing.T) {
+	// D has no incoming edges; C is a sink; {A, B} and {E, F} are components joined one way.
+	g, _ := graph.NewFromStruct(&graph.GraphJSON{Edges: []graph.Edge{
+		{From: "A", To: "B", Cost: 1},
+		{From: "B", To: "A", Cost: 1},
+		{From: "B", To: "C", Cost: 1},
+		{From: "D", To: "A", Cost: 1},
+		{From: "E", To: "F", Cost: 1},
+		{From: "F", To: "E", Cost: 1},
+		{From: "F", To: "A", Cost: 1},
+	}})
+	r := RunFloyd(g)
+	for _, tc := range []struct {
+		from, to, want string
+	}{
+		{"A", "D", "D has no incoming edges"},
+		{"C", "A", "C has no outgoing edges"},
+		{"A", "E", "no path from A to E: A reaches 2 other node(s) and 1 other node(s) reach E"},
+	} {
+		got, err := r.ExplainUnreachable(tc.from, tc.to)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.HasPrefix(got, tc.want) {
+			t.Errorf("%s -> %s: got %q, want prefix %q", tc.from, tc.to, got, tc.want)
+		}
+	}
+	if _, err := r.ExplainUnreachable("A", "C"); err == nil {
+		t.Error("expected error for a reachable pair")
+	}
+	if _, err := r.ExplainUnreachable("A", "zz"); err == nil {
+		t.Error("expected error for unknown node")
+	}
+
+	stub, _ := graph.NewFromStruct(&graph.GraphJSON{
+		Edges:      []graph.Edge{{From: "A", To: "M", Cost: 1}, {From: "M", To: "B", Cost: 1}},
+		NonTransit: []string{"M"},
+	})
+	got, err := RunFloyd(stub).ExplainUnreachable("A", "B")
+	if err != nil || !strings.Contains(got, "non-transit node M") {
+		t.Errorf("non-transit: got %q, %v", got, err)
+	}
+}
+
+func TestEccentricityRadiusDiameter(t *testing.T) {
+	// Undirected path A - B - C: B is the center.
+	g, _ := graph.NewFromStructWithOptions(&graph.GraphJSON{Edges: []graph.Edge{
+		{From: "A", To: "B", Cost: 1},
+		{From: "B", To: "C", Cost: 1},
+	}}, &graph.Options{Undirected: true})
+	r := RunFloyd(g)
+	for node, want := range map[string]int{"A": 2, "B": 1, "C": 2} {
+		if e, ok := r.Eccentricity(node); !ok || e != want {
+			t.Errorf("Eccentricity(%s) = %d, %v; want %d", node, e, ok, want)
+		}
+	}
+	if rad, ok := r.Radius(); !ok || rad != 1 {
+		t.Errorf("Radius = %d, %v; want 1", rad, ok)
+	}
+	if diam, ok := r.Diameter(); !ok || diam != 2 {
+		t.Errorf("Diameter = %d, %v; want 2", diam, ok)
+	}
+	if _, ok := r.Eccentricity("zz"); ok {
+		t.Error("unknown node should not have an eccentricity")
+	}
+
+	// Directed A -> B: A reaches everything, B does not.
+	dg, _ := graph.NewFromStruct(&graph.GraphJSON{Edges: []graph.Edge{{From: "A", To: "B", Cost: 3}}})
+	d := RunFloyd(dg)
+	if rad, ok := d.Radius(); !ok || rad != 3 {
+		t.Errorf("directed Radius = %d, %v; want 3", rad, ok)
+	}
+	if _, ok := d.Diameter(); ok {
+		t.Error("directed Diameter should be undefined")
+	}
+	vg, _ := graph.NewFromStruct(&graph.GraphJSON{Edges: []graph.Edge{{From: "A", To: "B", Cost: 1}, {From: "C", To: "B", Cost: 1}}})
+	if _, ok := RunFloyd(vg).Radius(); ok {
+		t.Error("no node reaches all others, Radius should be undefined")
+	}
+}
+
+func TestUnusedEdges(t *testing.T) {
+	// A->C costs 100 but A->B->C costs 20, so the direct link is redundant; C->A is the only way back.
+	g, _ := graph.NewFromStruct(&graph.GraphJSON{Edges: []graph.Edge{
+		{From: "A", To: "B", Cost: 10},
+		{From: "B", To: "C", Cost: 10},
+		{From: "A", To: "C", Cost: 100},
+		{From: "C", To: "A", Cost: 5},
+	}})
+	want := []graph.Edge{{From: "A", To: "C", Cost: 100}}
+	if got := RunFloyd(g).UnusedEdges(); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	if got := RunFloyd(weightedGraph(t)).UnusedEdges(); !reflect.DeepEqual(got, []graph.Edge{{From: "A", To: "C", Cost: 100}}) {
+		t.Errorf("weightedGraph: got %v", got)
+	}
+}
+
+func TestPairsDependentOnEdge(t *testing.T) {
+	// Two triangles {A, B, C} and {D, E, F} joined only by the bridge C -> D; A -> C also has an
+	// equal-cost alternative A -> B -> C.
+	g, _ := graph.NewFromStruct(&graph.GraphJSON{Edges: []graph.Edge{
+		{From: "A", To: "B", Cost: 1}, {From: "B", To: "C", Cost: 1}, {From: "A", To: "C", Cost: 2},
+		{From: "C", To: "D", Cost: 5},
+		{From: "D", To: "E", Cost: 1}, {From: "E", To: "F", Cost: 1},
+	}})
+	r := RunFloyd(g)
+	got, err := r.PairsDependentOnEdge("C", "D")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := [][2]string{
+		{"A", "D"}, {"A", "E"}, {"A", "F"},
+		{"B", "D"}, {"B", "E"}, {"B", "F"},
+		{"C", "D"}, {"C", "E"}, {"C", "F"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("bridge C -> D: got %v, want %v", got, want)
+	}
+	if got, err := r.PairsDependentOnEdge("A", "C"); err != nil || got != nil {
+		t.Errorf("A -> C has an equal-cost alternative: got %v, %v", got, err)
+	}
+	if got, _ := r.PairsDependentOnEdge("B", "C"); !reflect.DeepEqual(got, [][2]string{{"B", "C"}, {"B", "D"}, {"B", "E"}, {"B", "F"}}) {
+		t.Errorf("B -> C: got %v", got)
+	}
+	if _, err := r.PairsDependentOnEdge("D", "C"); err == nil {
+		t.Error("expected error for a missing edge")
+	}
+	if g.Cost(2, 3) == 0 {
+		t.Error("the result's graph must not be modified")
+	}
+}
+
+func TestNodeBetweenness(t *testing.T) {
+	// Undirected line A - B - C - D: B and C carry the through traffic.
+	line, _ := graph.NewFromStructWithOptions(&graph.GraphJSON{Edges: []graph.Edge{
+		{From: "A", To: "B", Cost: 1}, {From: "B", To: "C", Cost: 1}, {From: "C", To: "D", Cost: 1},
+	}}, &graph.Options{Undirected: true})
+	want := map[string]float64{"A": 0, "B": 4, "C": 4, "D": 0}
+	if got := RunFloyd(line).NodeBetweenness(); !reflect.DeepEqual(got, want) {
+		t.Errorf("line: got %v, want %v", got, want)
+	}
+
+	// Diamond A -> {B, C} -> D: the two middle nodes split A -> D evenly.
+	diamond, _ := graph.NewFromStruct(&graph.GraphJSON{Edges: []graph.Edge{
+		{From: "A", To: "B", Cost: 1}, {From: "A", To: "C", Cost: 1},
+		{From: "B", To: "D", Cost: 1}, {From: "C", To: "D", Cost: 1},
+	}})
+	want = map[string]float64{"A": 0, "B": 0.5, "C": 0.5, "D": 0}
+	if got := RunFloyd(diamond).NodeBetweenness(); !reflect.DeepEqual(got, want) {
+		t.Errorf("diamond: got %v, want %v", got, want)
+	}
+}
+
+func TestShortestPathSubgraph(t *testing.T) {
+	g, _ := graph.NewFromStruct(&graph.GraphJSON{Edges: []graph.Edge{
+		{From: "A", To: "B", Cost: 10, Capacity: 7},
+		{From: "B", To: "C", Cost: 10},
+		{From: "A", To: "C", Cost: 100},
+		{From: "C", To: "A", Cost: 5},
+	}})
+	r := RunFloyd(g)
+	sub := r.ShortestPathSubgraph()
+	if !reflect.DeepEqual(sub.Nodes, g.Nodes) {
+		t.Fatalf("nodes = %v, want %v", sub.Nodes, g.Nodes)
+	}
+	want := []graph.Edge{
+		{From: "A", To: "B", Cost: 10, Capacity: 7},
+		{From: "B", To: "C", Cost: 10},
+		{From: "C", To: "A", Cost: 5},
+	}
+	if got := sub.ToGraphJSON().Edges; !reflect.DeepEqual(got, want) {
+		t.Errorf("edges = %v, want %v", got, want)
+	}
+	// Distances are unchanged, since only unused edges were dropped.
+	if !reflect.DeepEqual(RunFloyd(sub).DistanceMatrix(), r.DistanceMatrix()) {
+		t.Error("subgraph distances differ from the original")
+	}
+}
+
+func TestShortestPathSubgraph_RawCosts(t *testing.T) {
+	// With a hop penalty the cost-1000 edge is 1010 in r's graph; the subgraph keeps 1000.
+	g, _ := graph.NewFromStruct(&graph.GraphJSON{Edges: []graph.Edge{
+		{From: "A", To: "B", Cost: graph.MaxCost}, {From: "B", To: "C", Cost: 1},
+	}})
+	r, err := RunFloydWithOptions(g, &Options{HopPenalty: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := r.ShortestPathSubgraph().ToGraphJSON().Edges, g.ToGraphJSON().Edges; !reflect.DeepEqual(got, want) {
+		t.Errorf("hop penalty: edges = %v, want %v", got, want)
+	}
+
+	// Negative costs from RunJohnson are copied as they are; A->B (4) is unused.
+	g = handBuiltGraph([]string{"A", "B", "C"}, [][]int{
+		{0, 4, 2},
+		{0, 0, 0},
+		{0, -3, 0},
+	})
+	r, err = RunJohnson(g)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []graph.Edge{{From: "A", To: "C", Cost: 2}, {From: "C", To: "B", Cost: -3}}
+	if got := r.ShortestPathSubgraph().ToGraphJSON().Edges; !reflect.DeepEqual(got, want) {
+		t.Errorf("negative costs: edges = %v, want %v", got, want)
+	}
+}
+
+func TestViaNeighborStretch(t *testing.T) {
+	g, _ := graph.NewFromStruct(&graph.GraphJSON{Edges: []graph.Edge{
+		{From: "A", To: "B", Cost: 10}, {From: "B", To: "A", Cost: 10},
+		{From: "A", To: "C", Cost: 10}, {From: "C", To: "A", Cost: 10},
+		{From: "B", To: "D", Cost: 10}, {From: "D", To: "B", Cost: 10},
+		{From: "C", To: "D", Cost: 10}, {From: "D", To: "C", Cost: 10},
+	}})
+	r := RunFloyd(g)
+	if _, ok := r.ViaNeighborStretch("A", "D"); ok {
+		t.Error("stretch should not be available before FillViaNeighborPaths")
+	}
+	r.FillViaNeighborPaths()
+	for _, pair := range [][2]string{{"A", "D"}, {"D", "A"}} {
+		got, ok := r.ViaNeighborStretch(pair[0], pair[1])
+		if !ok || got != 1.0 {
+			t.Errorf("%s->%s: got %v, %v; want 1, true", pair[0], pair[1], got, ok)
+		}
+	}
+	if _, ok := r.ViaNeighborStretch("A", "A"); ok {
+		t.Error("self pair should have no stretch")
+	}
+	if _, ok := r.ViaNeighborStretch("A", "Z"); ok {
+		t.Error("unknown node should have no stretch")
+	}
+}
+
+func TestTopByDistanceAndHops(t *testing.T) {
+	// Strongly connected triangle: B->A (5) is the diameter pair.
+	g, _ := graph.NewFromStruct(&graph.GraphJSON{Edges: []graph.Edge{
+		{From: "A", To: "B", Cost: 1}, {From: "B", To: "C", Cost: 2}, {From: "C", To: "A", Cost: 3},
+	}})
+	r := RunFloyd(g)
+	diameter, ok := r.Diameter()
+	top := r.TopByDistance(1)
+	if !ok || len(top) != 1 || top[0].From != "B" || top[0].To != "A" || top[0].Distance != diameter {
+		t.Errorf("top by distance = %v, want the diameter pair B->A %d", top, diameter)
+	}
+	if got := len(r.TopByDistance(10)); got != 6 {
+		t.Errorf("got %d pairs, want all 6 non-self pairs", got)
+	}
+
+	var got []string
+	for _, pr := range RunFloyd(weightedGraph(t)).TopByHops(10) {
+		got = append(got, pr.From+pr.To)
+	}
+	// A->C is the only two-hop pair; the one-hop ties are broken by name; C reaches nothing.
+	if want := []string{"AC", "AB", "BA", "BC"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("top by hops = %v, want %v", got, want)
+	}
+	if r.TopByDistance(0) != nil {
+		t.Error("n = 0 should return nil")
+	}
+}
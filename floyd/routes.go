@@ -0,0 +1,30 @@
+package floyd
+
+import (
+	"fmt"
+	"io"
+)
+
+// WriteStaticRoutes writes one "ip route <dest> via <nexthop>" line, in the static-route style
+// of Quagga and similar routers, for every destination reachable from source, in node order.
+// The next hop is the first node after source on the path to dest in source's shortest-path tree
+// (see ShortestPathTree), so ties resolve the same way.
+func WriteStaticRoutes(w io.Writer, r *AllPairsResult, source string) error {
+	i, ok := r.g.Index(source)
+	if !ok {
+		return fmt.Errorf("unknown node %q", source)
+	}
+	for j := 0; j < len(r.dist); j++ {
+		if j == i || r.dist[i][j] == Inf {
+			continue
+		}
+		hop := j
+		for p := r.treeParent(i, hop); p != i; p = r.treeParent(i, hop) {
+			hop = p
+		}
+		if _, err := fmt.Fprintf(w, "ip route %s via %s\n", r.g.Name(j), r.g.Name(hop)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
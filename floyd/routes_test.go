@@ -0,0 +1,26 @@
+package floyd
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestWriteStaticRoutes(t *testing.T) {
+	// A->C is cheaper through B (50+20) than direct (100).
+	r := RunFloyd(weightedGraph(t))
+	var buf bytes.Buffer
+	if err := WriteStaticRoutes(&buf, r, "A"); err != nil {
+		t.Fatal(err)
+	}
+	want := "ip route B via B\nip route C via B\n"
+	if buf.String() != want {
+		t.Errorf("got:\n%s\nwant:\n%s", buf.String(), want)
+	}
+	buf.Reset()
+	if err := WriteStaticRoutes(&buf, r, "C"); err != nil || buf.Len() != 0 {
+		t.Errorf("C reaches nothing: got %q, %v", buf.String(), err)
+	}
+	if err := WriteStaticRoutes(&buf, r, "zz"); err == nil {
+		t.Error("expected error for unknown node")
+	}
+}
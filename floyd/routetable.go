@@ -0,0 +1,60 @@
+package floyd
+
+import (
+	"sync"
+
+	"github.com/jursonmo/pathroute/graph"
+)
+
+// RouteTable pairs a graph with its all-pairs result for one "what-if" scenario. The result is
+// computed lazily on the first Lookup and cached; derived tables work on their own graph clone,
+// so the parent table is never affected.
+type RouteTable struct {
+	g    *graph.Graph
+	once sync.Once
+	res  *AllPairsResult
+}
+
+// NewRouteTable returns a route table over g. g should not be mutated afterwards.
+func NewRouteTable(g *graph.Graph) *RouteTable {
+	return &RouteTable{g: g}
+}
+
+// Graph returns the graph of this scenario.
+func (t *RouteTable) Graph() *graph.Graph { return t.g }
+
+// Result returns the all-pairs result, computing it on first use.
+func (t *RouteTable) Result() *AllPairsResult {
+	t.once.Do(func() {
+		t.res = RunFloyd(t.g)
+	})
+	return t.res
+}
+
+// WithEdgeRemoved returns a derived table whose graph is a clone of t's without edge from -> to.
+func (t *RouteTable) WithEdgeRemoved(from, to string) (*RouteTable, error) {
+	g := t.g.Clone()
+	if err := g.RemoveEdge(from, to); err != nil {
+		return nil, err
+	}
+	return NewRouteTable(g), nil
+}
+
+// Lookup returns the first shortest path from -> to; ok is false if either node is unknown or
+// to is unreachable from from.
+func (t *RouteTable) Lookup(from, to string) (PathDist, bool) {
+	i, ok := t.g.Index(from)
+	if !ok {
+		return PathDist{}, false
+	}
+	j, ok := t.g.Index(to)
+	if !ok {
+		return PathDist{}, false
+	}
+	r := t.Result()
+	pr := *r.result(i, j)
+	if r.dist[i][j] == Inf || len(pr.Paths) == 0 {
+		return PathDist{}, false
+	}
+	return pr.Paths[0], true
+}
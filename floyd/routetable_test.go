@@ -0,0 +1,30 @@
+package floyd
+
+import "testing"
+
+func TestRouteTable_WithEdgeRemoved(t *testing.T) {
+	base := NewRouteTable(weightedGraph(t))
+	p, ok := base.Lookup("A", "C")
+	if !ok || p.Distance != 70 {
+		t.Fatalf("base A->C: %v %v", p, ok)
+	}
+	derived, err := base.WithEdgeRemoved("B", "C")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p, ok := derived.Lookup("A", "C"); !ok || p.Distance != 100 || len(p.Path) != 2 {
+		t.Errorf("derived A->C should be direct 100: %v %v", p, ok)
+	}
+	if p, ok := derived.Lookup("B", "C"); !ok || p.Distance != 180 {
+		t.Errorf("derived B->C should detour via A (180): %v %v", p, ok)
+	}
+	if p, ok := base.Lookup("A", "C"); !ok || p.Distance != 70 {
+		t.Errorf("base A->C changed: %v %v", p, ok)
+	}
+	if _, err := base.WithEdgeRemoved("C", "A"); err == nil {
+		t.Error("expected error removing missing edge")
+	}
+	if _, ok := base.Lookup("A", "X"); ok {
+		t.Error("unknown node should not be found")
+	}
+}
@@ -0,0 +1,68 @@
+package floyd
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/jursonmo/pathroute/graph"
+)
+
+// MaxSimplePaths caps how many paths AllSimplePaths returns.
+const MaxSimplePaths = 10000
+
+// ErrTooManyPaths is returned (wrapped) when an enumeration hit its cap and the returned
+// paths are truncated.
+var ErrTooManyPaths = errors.New("too many paths")
+
+// AllSimplePaths returns every simple path from -> to with at most maxHops edges, found by DFS
+// with a visited set. At most MaxSimplePaths paths are returned; if more exist, the truncated
+// list is returned together with an error wrapping ErrTooManyPaths.
+func AllSimplePaths(g *graph.Graph, from, to string, maxHops int) ([][]string, error) {
+	s, ok := g.Index(from)
+	if !ok {
+		return nil, fmt.Errorf("unknown node %q", from)
+	}
+	t, ok := g.Index(to)
+	if !ok {
+		return nil, fmt.Errorf("unknown node %q", to)
+	}
+	var out [][]string
+	visited := make([]bool, g.NumNodes())
+	path := []int{s}
+	visited[s] = true
+	var dfs func(u int) bool
+	dfs = func(u int) bool {
+		if u == t {
+			if len(out) >= MaxSimplePaths {
+				return false
+			}
+			names := make([]string, len(path))
+			for i, idx := range path {
+				names[i] = g.Name(idx)
+			}
+			out = append(out, names)
+			return true
+		}
+		if len(path)-1 >= maxHops {
+			return true
+		}
+		for _, v := range g.Neighbors(u) {
+			if visited[v] {
+				continue
+			}
+			visited[v] = true
+			path = append(path, v)
+			ok := dfs(v)
+			path = path[:len(path)-1]
+			visited[v] = false
+			if !ok {
+				return false
+			}
+		}
+		return true
+	}
+	if !dfs(s) {
+		return out, fmt.Errorf("%w: more than %d paths from %s to %s", ErrTooManyPaths, MaxSimplePaths, from, to)
+	}
+	return out, nil
+}
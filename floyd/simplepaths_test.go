@@ -0,0 +1,36 @@
+package floyd
+
+import (
+	"testing"
+
+	"github.com/jursonmo/pathroute/graph"
+)
+
+func TestAllSimplePaths(t *testing.T) {
+	// A->B->D, A->C->D, A->B->C->D, A->D, plus D->A which must not create cycles.
+	g, _ := graph.NewFromStruct(&graph.GraphJSON{
+		Edges: []graph.Edge{
+			{From: "A", To: "B", Cost: 1},
+			{From: "A", To: "C", Cost: 1},
+			{From: "A", To: "D", Cost: 9},
+			{From: "B", To: "C", Cost: 1},
+			{From: "B", To: "D", Cost: 1},
+			{From: "C", To: "D", Cost: 1},
+			{From: "D", To: "A", Cost: 1},
+		},
+	})
+	paths, err := AllSimplePaths(g, "A", "D", 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(paths) != 4 {
+		t.Errorf("simple paths within 3 hops: got %d %v, want 4", len(paths), paths)
+	}
+	paths, _ = AllSimplePaths(g, "A", "D", 2)
+	if len(paths) != 3 {
+		t.Errorf("simple paths within 2 hops: got %d %v, want 3", len(paths), paths)
+	}
+	if _, err := AllSimplePaths(g, "A", "X", 3); err == nil {
+		t.Error("expected error for unknown node")
+	}
+}
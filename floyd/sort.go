@@ -0,0 +1,28 @@
+package floyd
+
+import "sort"
+
+// Sort modes accepted by SortResults.
+const (
+	SortByPair     = "pair"     // leave Results order (From index, then To index)
+	SortByDistance = "distance" // ascending shortest distance, unreachable pairs last
+)
+
+// SortResults stably reorders results in place according to mode. SortByPair (and any
+// unknown mode) leaves the order unchanged; SortByDistance sorts reachable pairs by ascending
+// Distance, keeping the current order among equal distances, with unreachable pairs last. A pair
+// is unreachable if its Distance is Unreachable and it has no Paths, so negative distances of
+// RunJohnson results sort first.
+func SortResults(results []PairResult, mode string) {
+	if mode != SortByDistance {
+		return
+	}
+	unreachable := func(pr *PairResult) bool { return pr.Distance == Unreachable && len(pr.Paths) == 0 }
+	sort.SliceStable(results, func(a, b int) bool {
+		ua, ub := unreachable(&results[a]), unreachable(&results[b])
+		if ua || ub {
+			return ub && !ua
+		}
+		return results[a].Distance < results[b].Distance
+	})
+}
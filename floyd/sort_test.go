@@ -0,0 +1,24 @@
+package floyd
+
+import "testing"
+
+func TestSortResults(t *testing.T) {
+	results := RunFloyd(weightedGraph(t)).Results
+	SortResults(results, SortByDistance)
+	var got []string
+	for _, pr := range results {
+		if pr.From != pr.To {
+			got = append(got, pr.From+pr.To)
+		}
+	}
+	// B->C 20, A->B 50, A->C 70, B->A 80, then unreachable C->A, C->B in their original order.
+	want := []string{"BC", "AB", "AC", "BA", "CA", "CB"}
+	if len(got) != len(want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	for k := range want {
+		if got[k] != want[k] {
+			t.Fatalf("got %v, want %v", got, want)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package floyd
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/jursonmo/pathroute/graph"
+)
+
+// SourceRouter answers single-source queries without computing all pairs: each source is routed
+// with Dijkstra on its first query and cached. It suits large graphs where only a few sources
+// are ever needed. It is safe for concurrent use; g should not be mutated afterwards.
+type SourceRouter struct {
+	g    *graph.Graph
+	cost [][]int
+	skip []bool
+	nw   []int
+	mu   sync.Mutex
+	rows map[int][]PathDist
+}
+
+// NewSourceRouter returns a SourceRouter over g. No routing is done until From is called.
+func NewSourceRouter(g *graph.Graph) *SourceRouter {
+	return &SourceRouter{g: g, cost: g.CostMatrix(), skip: nonTransitMask(g), nw: nodeWeightVector(g), rows: make(map[int][]PathDist)}
+}
+
+// From returns one shortest path from source to every node, indexed like the graph's nodes.
+// Unreachable nodes have Distance Unreachable and no path; source itself has the one-node path.
+// Repeated calls return the same cached slice, which must not be modified.
+func (s *SourceRouter) From(source string) ([]PathDist, error) {
+	src, ok := s.g.Index(source)
+	if !ok {
+		return nil, fmt.Errorf("unknown node %q", source)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if row, ok := s.rows[src]; ok {
+		return row, nil
+	}
+	n := len(s.cost)
+	cost := withNodeWeights(func(i, j int) int { return s.cost[i][j] }, src, s.nw)
+	dist, parent := dijkstraTree(n, src, func(i, j int) (int, bool) {
+		w := cost(i, j)
+		return w, w > 0
+	}, s.skip)
+	row := make([]PathDist, n)
+	for j := range row {
+		if dist[j] == Inf {
+			row[j] = PathDist{Distance: Unreachable}
+			continue
+		}
+		var path []string
+		for v := j; v >= 0; v = parent[v] {
+			path = append(path, s.g.Name(v))
+		}
+		for a, b := 0, len(path)-1; a < b; a, b = a+1, b-1 {
+			path[a], path[b] = path[b], path[a]
+		}
+		row[j] = PathDist{Path: path, Distance: dist[j]}
+	}
+	s.rows[src] = row
+	return row, nil
+}
+
+// Computed returns the sources routed so far, in node order.
+func (s *SourceRouter) Computed() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var out []string
+	for i := 0; i < len(s.cost); i++ {
+		if _, ok := s.rows[i]; ok {
+			out = append(out, s.g.Name(i))
+		}
+	}
+	return out
+}
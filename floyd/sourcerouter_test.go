@@ -0,0 +1,43 @@
+package floyd
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSourceRouter(t *testing.T) {
+	g := weightedGraph(t)
+	s := NewSourceRouter(g)
+	if got := s.Computed(); got != nil {
+		t.Fatalf("nothing should be computed yet, got %v", got)
+	}
+	first, err := s.From("A")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []PathDist{
+		{Path: []string{"A"}, Distance: 0},
+		{Path: []string{"A", "B"}, Distance: 50},
+		{Path: []string{"A", "B", "C"}, Distance: 70},
+	}
+	if !reflect.DeepEqual(first, want) {
+		t.Errorf("From(A) = %v, want %v", first, want)
+	}
+	second, _ := s.From("A")
+	if !reflect.DeepEqual(first, second) {
+		t.Errorf("repeated From(A) differs: %v vs %v", first, second)
+	}
+	if got := s.Computed(); !reflect.DeepEqual(got, []string{"A"}) {
+		t.Errorf("Computed = %v, want [A]", got)
+	}
+	fromC, _ := s.From("C")
+	if fromC[0].Distance != Unreachable || fromC[0].Path != nil {
+		t.Errorf("C->A should be unreachable: %v", fromC[0])
+	}
+	if got := s.Computed(); !reflect.DeepEqual(got, []string{"A", "C"}) {
+		t.Errorf("Computed = %v, want [A C]", got)
+	}
+	if _, err := s.From("zz"); err == nil {
+		t.Error("expected error for unknown node")
+	}
+}
@@ -0,0 +1,70 @@
+package floyd
+
+import (
+	"math/rand"
+	"sort"
+
+	"github.com/jursonmo/pathroute/graph"
+)
+
+// TieBreak selects how equal-distance paths are ordered before PairResult.Paths is truncated to
+// the path cap (see Options.TieBreak).
+type TieBreak int
+
+const (
+	// AsDiscovered keeps the enumeration order: by node index sequence (see PairResult).
+	AsDiscovered TieBreak = iota
+	// FewestHops prefers paths with fewer edges, then AsDiscovered.
+	FewestHops
+	// Lexicographic orders paths by their node names, compared element by element.
+	Lexicographic
+)
+
+// kShortestTieBroken is KShortestSimplePaths with equal-distance paths ordered by tb. Unless tb is
+// AsDiscovered (and rng is nil) it keeps enumerating until the k-th distance is exceeded, so every
+// path tied with the k-th one competes for the last slots; at most MaxSimplePaths candidates are
+// considered. A non-nil rng shuffles the candidates first, so paths tb does not tell apart are
+// picked in random order.
+func kShortestTieBroken(g *graph.Graph, fromIdx, toIdx, k int, tb TieBreak, rng *rand.Rand) []PathDist {
+	if tb == AsDiscovered && rng == nil || k <= 0 {
+		return KShortestSimplePaths(g, fromIdx, toIdx, k)
+	}
+	var out []PathDist
+	walkSimplePaths(g, fromIdx, toIdx, g.Cost, func(p PathDist) bool {
+		if len(out) >= k && p.Distance > out[k-1].Distance {
+			return false
+		}
+		out = append(out, p)
+		return len(out) < MaxSimplePaths
+	})
+	if rng != nil {
+		rng.Shuffle(len(out), func(a, b int) { out[a], out[b] = out[b], out[a] })
+	}
+	sort.SliceStable(out, func(a, b int) bool {
+		pa, pb := out[a], out[b]
+		if pa.Distance != pb.Distance {
+			return pa.Distance < pb.Distance
+		}
+		switch tb {
+		case FewestHops:
+			return len(pa.Path) < len(pb.Path)
+		case Lexicographic:
+			return lessNames(pa.Path, pb.Path)
+		}
+		return false
+	})
+	if len(out) > k {
+		out = out[:k]
+	}
+	return out
+}
+
+// lessNames compares two paths by node name lexicographically; a proper prefix is smaller.
+func lessNames(a, b []string) bool {
+	for k := 0; k < len(a) && k < len(b); k++ {
+		if a[k] != b[k] {
+			return a[k] < b[k]
+		}
+	}
+	return len(a) < len(b)
+}
@@ -0,0 +1,66 @@
+package floyd
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/jursonmo/pathroute/graph"
+)
+
+func TestTieBreak(t *testing.T) {
+	// A->C->E->D (3 hops) and A->B->D (2 hops) both cost 4; by node index the 3-hop path comes first.
+	g, err := graph.NewFromStruct(&graph.GraphJSON{
+		Nodes: []string{"A", "C", "E", "B", "D"},
+		Edges: []graph.Edge{
+			{From: "A", To: "C", Cost: 1}, {From: "C", To: "E", Cost: 1}, {From: "E", To: "D", Cost: 2},
+			{From: "A", To: "B", Cost: 2}, {From: "B", To: "D", Cost: 2},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range []struct {
+		tb   TieBreak
+		want string
+	}{
+		{AsDiscovered, "A|C|E|D"},
+		{FewestHops, "A|B|D"},
+		{Lexicographic, "A|B|D"},
+	} {
+		r, err := RunFloydWithOptions(g, &Options{TieBreak: tc.tb, Limits: &graph.Limits{MaxShortestPaths: 1}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		pr := findResult(r, "A", "D")
+		if len(pr.Paths) != 1 || JoinPathKey(pr.Paths[0].Path) != tc.want {
+			t.Errorf("TieBreak %d: got %v, want %s", tc.tb, pr.Paths, tc.want)
+		}
+	}
+}
+
+func TestShuffleSeed(t *testing.T) {
+	// r0c0 -> r3c3 in a 4x4 grid has 20 equal-cost paths, of which only 4 are kept.
+	g := graph.GridGraph(4, 4)
+	paths := func(opts *Options) []PathDist {
+		r, err := RunFloydWithOptions(g, opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return findResult(r, "r0c0", "r3c3").Paths
+	}
+	a := paths(&Options{Shuffle: true, Seed: 1})
+	b := paths(&Options{Shuffle: true, Seed: 1})
+	c := paths(&Options{Shuffle: true, Seed: 2})
+	if len(a) != MaxShortestPaths {
+		t.Fatalf("got %d paths, want %d", len(a), MaxShortestPaths)
+	}
+	if !reflect.DeepEqual(a, b) {
+		t.Errorf("same seed gave different paths:\n%v\n%v", a, b)
+	}
+	if reflect.DeepEqual(a, c) {
+		t.Errorf("seeds 1 and 2 gave the same paths: %v", a)
+	}
+	if d := paths(nil); !reflect.DeepEqual(d, paths(nil)) {
+		t.Error("without Shuffle the paths should be deterministic")
+	}
+}
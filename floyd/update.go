@@ -0,0 +1,107 @@
+package floyd
+
+import "fmt"
+
+// UpdateEdge sets the cost of edge from -> to (adding it if absent, under the rules of
+// graph.Graph.AddEdge) in the underlying graph and refreshes distances, predecessors and
+// Results. A decreased (or new) edge is applied with the O(N²) Floyd update
+// dist[i][j] = min(dist[i][j], dist[i][from]+w+dist[to][j]); an increase can invalidate existing
+// shortest paths, so it falls back to a full recompute. Only pairs (i, j) with i reaching from
+// and to reaching j can route over the edge, so only their Results are rebuilt.
+// ViaNeighborPaths are not touched; call FillViaNeighborPaths again to refresh them. Results
+// computed with Options.DistancesOnly stay without Paths; with Options.SinglePathOnly the first
+// hops are updated instead of the predecessor lists and each pair keeps a single path. With
+// Options.HopPenalty, newWeight is the raw cost: the penalized copy gets newWeight plus the
+// penalty, and rebuilt paths report their raw cost as in the original result.
+func (r *AllPairsResult) UpdateEdge(from, to string, newWeight int) error {
+	g := r.g
+	u, ok := g.Index(from)
+	if !ok {
+		return fmt.Errorf("unknown node %q", from)
+	}
+	v, ok := g.Index(to)
+	if !ok {
+		return fmt.Errorf("unknown node %q", to)
+	}
+	old := g.Cost(u, v)
+	if r.hopPenalty > 0 {
+		// g is the penalized copy (see Options.HopPenalty), whose cost range is shifted to match.
+		if lim := g.Limits.Resolved(); newWeight+r.hopPenalty < lim.MinCost || newWeight+r.hopPenalty > lim.MaxCost {
+			return fmt.Errorf("edge %s -> %s cost %d out of range [%d, %d]", from, to, newWeight,
+				lim.MinCost-r.hopPenalty, lim.MaxCost-r.hopPenalty)
+		}
+		newWeight += r.hopPenalty
+	}
+	if err := g.AddEdge(from, to, newWeight); err != nil {
+		return err
+	}
+	N := g.NumNodes()
+	if r.next == nil {
+		r.predecessors() // make sure pred exists before it is updated in place
+	}
+	if old != 0 && newWeight > old {
+		if r.next != nil {
+			r.dist, r.next = floydNextHops(g, Inf)
+		} else {
+			r.dist, r.pred = floydWarshall(g)
+		}
+	} else {
+		// With non-transit nodes, u may only start and v only end a path through the new edge.
+		skip := nonTransitMask(g)
+		nw := nodeWeightVector(g)
+		for i := 0; i < N; i++ {
+			if r.dist[i][u] == Inf || i != u && skip != nil && skip[u] {
+				continue
+			}
+			for j := 0; j < N; j++ {
+				if r.dist[v][j] == Inf || j != v && skip != nil && skip[v] {
+					continue
+				}
+				d := r.dist[i][u] + newWeight + r.dist[v][j]
+				if nw != nil && i != u {
+					d += nw[u]
+				}
+				if nw != nil && j != v {
+					d += nw[v]
+				}
+				if d < r.dist[i][j] {
+					r.dist[i][j] = d
+					if r.next != nil && i == u {
+						r.next[i][j] = v
+					} else if r.next != nil {
+						r.next[i][j] = r.next[i][u]
+					}
+				}
+			}
+		}
+		for i := 0; i < N && r.next == nil; i++ {
+			r.pred[i] = predRow(N, i, r.dist[i], g.Cost, skip, nw)
+		}
+	}
+	maxPaths := r.limits.Resolved().MaxShortestPaths
+	for i := 0; i < N; i++ {
+		if r.dist[i][u] == Inf {
+			continue
+		}
+		for j := 0; j < N; j++ {
+			if r.dist[v][j] == Inf {
+				continue
+			}
+			var paths []PathDist
+			switch {
+			case r.dist[i][j] == Inf || r.distancesOnly:
+			case r.next != nil:
+				paths = []PathDist{{Path: nextHopPath(g, r.next, i, j), Distance: r.dist[i][j]}}
+			default:
+				paths = kShortestTieBroken(g, i, j, maxPaths, r.tieBreak, r.rng)
+			}
+			if r.hopPenalty > 0 {
+				r.annotateRawCost(paths)
+			}
+			pr := newPairResult(g, i, j, r.dist[i][j], paths)
+			pr.ViaNeighborPaths = r.result(i, j).ViaNeighborPaths
+			*r.result(i, j) = pr
+		}
+	}
+	return nil
+}
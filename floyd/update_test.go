@@ -0,0 +1,94 @@
+package floyd
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/jursonmo/pathroute/graph"
+)
+
+func TestUpdateEdge(t *testing.T) {
+	gj := &graph.GraphJSON{
+		Nodes: []string{"A", "B", "C", "D"},
+		Edges: []graph.Edge{
+			{From: "A", To: "B", Cost: 10},
+			{From: "B", To: "D", Cost: 10},
+			{From: "A", To: "C", Cost: 15},
+			{From: "C", To: "D", Cost: 10},
+		},
+	}
+	g, _ := graph.NewFromStruct(gj)
+	r := RunFloyd(g)
+	if ad := findResult(r, "A", "D"); ad.Distance != 20 || ad.Paths[0].Path[1] != "B" {
+		t.Fatalf("A->D before update: %v", ad)
+	}
+
+	// Decrease A->C: A->C->D (5+10) now beats A->B->D (20).
+	if err := r.UpdateEdge("A", "C", 5); err != nil {
+		t.Fatal(err)
+	}
+	if ad := findResult(r, "A", "D"); ad.Distance != 15 || ad.Paths[0].Path[1] != "C" {
+		t.Errorf("A->D after decrease: %v", ad)
+	}
+	g2, _ := graph.NewFromStruct(gj)
+	_ = g2.AddEdge("A", "C", 5)
+	if fresh := RunFloyd(g2); !reflect.DeepEqual(r.Results, fresh.Results) || !reflect.DeepEqual(r.dist, fresh.dist) ||
+		!reflect.DeepEqual(r.pred, fresh.pred) {
+		t.Errorf("incremental decrease differs from fresh RunFloyd")
+	}
+
+	// Increase A->C back above the A->B->D route.
+	if err := r.UpdateEdge("A", "C", 50); err != nil {
+		t.Fatal(err)
+	}
+	_ = g2.AddEdge("A", "C", 50)
+	if fresh := RunFloyd(g2); !reflect.DeepEqual(r.Results, fresh.Results) || !reflect.DeepEqual(r.dist, fresh.dist) {
+		t.Errorf("recompute after increase differs from fresh RunFloyd")
+	}
+
+	if err := r.UpdateEdge("A", "X", 5); err == nil {
+		t.Error("expected error for unknown node")
+	}
+}
+
+func TestUpdateEdge_HopPenalty(t *testing.T) {
+	gj := &graph.GraphJSON{Edges: []graph.Edge{
+		{From: "A", To: "B", Cost: 50}, {From: "B", To: "D", Cost: 50},
+		{From: "A", To: "C", Cost: 30}, {From: "C", To: "E", Cost: 30}, {From: "E", To: "D", Cost: 35},
+	}}
+	g, _ := graph.NewFromStruct(gj)
+	opts := &Options{HopPenalty: 10, TieBreak: Lexicographic}
+	r, err := RunFloydWithOptions(g, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A->D at raw cost 105 is penalized to 115, beating A->B->D at 120.
+	if err := r.UpdateEdge("A", "D", 105); err != nil {
+		t.Fatal(err)
+	}
+	best := findResult(r, "A", "D").Paths[0]
+	if JoinPathKey(best.Path) != "A|D" || best.Distance != 115 || best.Metrics[graph.CostMetric] != 105 {
+		t.Errorf("A->D after update: got %v, want A|D with distance 115 and raw cost 105", best)
+	}
+	if g.Cost(0, 1) != 50 {
+		t.Error("input graph was modified")
+	}
+	g2, _ := graph.NewFromStruct(gj)
+	_ = g2.AddEdge("A", "D", 105)
+	fresh, err := RunFloydWithOptions(g2, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(r.Results, fresh.Results) || !reflect.DeepEqual(r.dist, fresh.dist) {
+		t.Errorf("incremental update differs from fresh RunFloydWithOptions")
+	}
+
+	// The cost range applies to the raw cost, not the penalized one.
+	if err := r.UpdateEdge("A", "D", graph.MaxCost); err != nil {
+		t.Errorf("raw cost %d rejected: %v", graph.MaxCost, err)
+	}
+	if err := r.UpdateEdge("A", "D", graph.MaxCost+1); err == nil {
+		t.Errorf("raw cost %d accepted", graph.MaxCost+1)
+	}
+}
@@ -0,0 +1,71 @@
+package floyd
+
+import (
+	"fmt"
+
+	"github.com/jursonmo/pathroute/graph"
+)
+
+// WidestPath returns the path from -> to that maximizes the minimum edge capacity (the widest
+// or max-bottleneck path) together with that bottleneck capacity. It runs Dijkstra with the
+// (max, min) semiring over g.Capacity; edges without an explicit capacity use their cost.
+// Non-transit nodes are not passed through. Among equally wide candidates the first one settled
+// wins. It errors if a name is unknown, from == to, or to is unreachable.
+func WidestPath(g *graph.Graph, from, to string) ([]string, int, error) {
+	s, ok := g.Index(from)
+	if !ok {
+		return nil, 0, fmt.Errorf("unknown node %q", from)
+	}
+	t, ok := g.Index(to)
+	if !ok {
+		return nil, 0, fmt.Errorf("unknown node %q", to)
+	}
+	if s == t {
+		return nil, 0, fmt.Errorf("from and to must differ: %q", from)
+	}
+	N := g.NumNodes()
+	width := make([]int, N) // 0 = not reached
+	parent := make([]int, N)
+	done := make([]bool, N)
+	for i := range parent {
+		parent[i] = -1
+	}
+	width[s] = Inf
+	for {
+		u := -1
+		for i := 0; i < N; i++ {
+			if !done[i] && width[i] > 0 && (u < 0 || width[i] > width[u]) {
+				u = i
+			}
+		}
+		if u < 0 || u == t {
+			break
+		}
+		done[u] = true
+		if u != s && !g.Transit(u) {
+			continue
+		}
+		for v := 0; v < N; v++ {
+			c := g.Capacity(u, v)
+			if c <= 0 || done[v] {
+				continue
+			}
+			if w := min(width[u], c); w > width[v] {
+				width[v] = w
+				parent[v] = u
+			}
+		}
+	}
+	if width[t] == 0 {
+		return nil, 0, fmt.Errorf("%s is unreachable from %s", to, from)
+	}
+	var rev []string
+	for v := t; v != -1; v = parent[v] {
+		rev = append(rev, g.Name(v))
+	}
+	path := make([]string, len(rev))
+	for i := range rev {
+		path[i] = rev[len(rev)-1-i]
+	}
+	return path, width[t], nil
+}
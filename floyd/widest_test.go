@@ -0,0 +1,36 @@
+package floyd
+
+import (
+	"testing"
+
+	"github.com/jursonmo/pathroute/graph"
+)
+
+func TestWidestPath(t *testing.T) {
+	// Cheapest A->D is A->B->D (cost 2) but its bottleneck is 10; A->C->D costs more but
+	// carries 40. C->D has no explicit capacity, so it defaults to its cost (50).
+	g, _ := graph.NewFromStruct(&graph.GraphJSON{
+		Edges: []graph.Edge{
+			{From: "A", To: "B", Cost: 1, Capacity: 100},
+			{From: "B", To: "D", Cost: 1, Capacity: 10},
+			{From: "A", To: "C", Cost: 5, Capacity: 40},
+			{From: "C", To: "D", Cost: 50},
+		},
+	})
+	path, width, err := WidestPath(g, "A", "D")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if width != 40 || len(path) != 3 || path[1] != "C" {
+		t.Errorf("widest A->D: got %v width %d, want [A C D] width 40", path, width)
+	}
+	if ad := findResult(RunFloyd(g), "A", "D"); ad.Paths[0].Path[1] != "B" {
+		t.Errorf("shortest A->D should go via B: %v", ad.Paths[0])
+	}
+	if _, _, err := WidestPath(g, "D", "A"); err == nil {
+		t.Error("expected error for unreachable pair")
+	}
+	if _, _, err := WidestPath(g, "A", "A"); err == nil {
+		t.Error("expected error for from == to")
+	}
+}
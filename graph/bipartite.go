@@ -0,0 +1,55 @@
+package graph
+
+// IsBipartite reports whether the undirected version of g (every edge taken in both directions)
+// is bipartite, by BFS 2-coloring each component from its lowest-index node. If it is, the
+// result holds the two sides, each in index order; isolated nodes go to the first side. If not,
+// it holds the BFS tree paths from the component's root to the two endpoints of an edge whose
+// endpoints got the same color; together with that edge they form an odd cycle.
+func (g *Weighted[W]) IsBipartite() (bool, [2][]string) {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	N := len(g.Nodes)
+	adjacent := func(i, j int) bool { return g.AdjMatrix[i][j] != 0 || g.AdjMatrix[j][i] != 0 }
+	color := make([]int, N) // 0 = unvisited, 1 or 2 = side
+	parent := make([]int, N)
+	for root := 0; root < N; root++ {
+		if color[root] != 0 {
+			continue
+		}
+		color[root], parent[root] = 1, -1
+		queue := []int{root}
+		for len(queue) > 0 {
+			u := queue[0]
+			queue = queue[1:]
+			for v := 0; v < N; v++ {
+				if v == u || !adjacent(u, v) {
+					continue
+				}
+				if color[v] == 0 {
+					color[v], parent[v] = 3-color[u], u
+					queue = append(queue, v)
+				} else if color[v] == color[u] {
+					return false, [2][]string{g.rootPath(parent, u), g.rootPath(parent, v)}
+				}
+			}
+		}
+	}
+	var sides [2][]string
+	for i, n := range g.Nodes {
+		sides[color[i]-1] = append(sides[color[i]-1], n)
+	}
+	return true, sides
+}
+
+// rootPath returns the names on the BFS tree path from the root to v, following parent.
+func (g *Weighted[W]) rootPath(parent []int, v int) []string {
+	var rev []string
+	for ; v != -1; v = parent[v] {
+		rev = append(rev, g.Nodes[v])
+	}
+	path := make([]string, len(rev))
+	for i := range rev {
+		path[i] = rev[len(rev)-1-i]
+	}
+	return path
+}
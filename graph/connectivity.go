@@ -0,0 +1,34 @@
+package graph
+
+// IsWeaklyConnected reports whether every node can be reached from every other when edge
+// direction is ignored, using union-find over the edges. Unlike strong connectivity it does not
+// mean every pair is routable. A graph with no nodes counts as connected.
+func (g *Weighted[W]) IsWeaklyConnected() bool {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	N := len(g.Nodes)
+	parent := make([]int, N)
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(int) int
+	find = func(x int) int {
+		if parent[x] != x {
+			parent[x] = find(parent[x])
+		}
+		return parent[x]
+	}
+	components := N
+	for i, row := range g.AdjMatrix {
+		for j, w := range row {
+			if w == 0 {
+				continue
+			}
+			if ri, rj := find(i), find(j); ri != rj {
+				parent[ri] = rj
+				components--
+			}
+		}
+	}
+	return components <= 1
+}
@@ -0,0 +1,86 @@
+package graph
+
+import "fmt"
+
+// Contract returns a new graph in which the nodes of group are replaced by one supernode called
+// name, placed at the position of the group's first node in index order. Edges inside the group
+// are dropped; for each external neighbor the supernode keeps the cheapest edge to it and the
+// cheapest edge from it, with that edge's capacity. The supernode is a transit node without a
+// node weight; Metrics and Meta are not carried over. It errors if group is empty or has an
+// unknown node, or name is already an external node.
+func (g *Weighted[W]) Contract(group []string, name string) (*Weighted[W], error) {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	if len(group) == 0 {
+		return nil, fmt.Errorf("empty group")
+	}
+	inGroup := make(map[int]bool, len(group))
+	for _, n := range group {
+		i, ok := g.NameToIndex[n]
+		if !ok {
+			return nil, fmt.Errorf("unknown node %q", n)
+		}
+		inGroup[i] = true
+	}
+	if i, ok := g.NameToIndex[name]; ok && !inGroup[i] {
+		return nil, fmt.Errorf("supernode name %q is already a node outside the group", name)
+	}
+	oldN := len(g.Nodes)
+	oldToNew := make([]int, oldN)
+	var nodes []string
+	super := -1
+	for i := 0; i < oldN; i++ {
+		if inGroup[i] {
+			if super < 0 {
+				super = len(nodes)
+				nodes = append(nodes, name)
+			}
+			oldToNew[i] = super
+			continue
+		}
+		oldToNew[i] = len(nodes)
+		nodes = append(nodes, g.Nodes[i])
+	}
+	N := len(nodes)
+	adj := newMatrix[W](N)
+	capm := newMatrix[W](N)
+	for i := 0; i < oldN; i++ {
+		for j := 0; j < oldN; j++ {
+			w := g.AdjMatrix[i][j]
+			if w == 0 || inGroup[i] && inGroup[j] {
+				continue
+			}
+			ni, nj := oldToNew[i], oldToNew[j]
+			if adj[ni][nj] == 0 || w < adj[ni][nj] {
+				adj[ni][nj] = w
+				capm[ni][nj] = g.explicitCapacity(i, j)
+			}
+		}
+	}
+	nameToIndex := make(map[string]int, N)
+	for i, n := range nodes {
+		nameToIndex[n] = i
+	}
+	var nonTransit map[string]bool
+	for n := range g.NonTransit {
+		if i, ok := g.NameToIndex[n]; ok && !inGroup[i] {
+			if nonTransit == nil {
+				nonTransit = make(map[string]bool)
+			}
+			nonTransit[n] = true
+		}
+	}
+	weights := copyNodeWeights(g.NodeWeights, nameToIndex)
+	delete(weights, name)
+	return &Weighted[W]{
+		Nodes:       nodes,
+		NameToIndex: nameToIndex,
+		AdjMatrix:   adj,
+		CapMatrix:   capm,
+		Limits:      g.Limits,
+		NonTransit:  nonTransit,
+		Attributes:  copyAttributes(g.Attributes, nameToIndex),
+		NodeWeights: weights,
+		unbounded:   g.unbounded,
+	}, nil
+}
@@ -0,0 +1,44 @@
+package graph
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestContract(t *testing.T) {
+	g, err := NewFromStruct(&GraphJSON{Edges: []Edge{
+		{From: "X", To: "A", Cost: 30},
+		{From: "X", To: "B", Cost: 10},
+		{From: "A", To: "B", Cost: 5},
+		{From: "A", To: "Y", Cost: 7},
+		{From: "B", To: "Y", Cost: 9},
+		{From: "Y", To: "X", Cost: 1},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := g.Contract([]string{"A", "B"}, "AB")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"X", "AB", "Y"}; !reflect.DeepEqual(c.Nodes, want) {
+		t.Fatalf("nodes = %v, want %v", c.Nodes, want)
+	}
+	want := [][]int{
+		{0, 10, 0}, // X->AB keeps min(30, 10)
+		{0, 0, 7},  // AB->Y keeps min(7, 9); A->B is dropped
+		{1, 0, 0},
+	}
+	if !reflect.DeepEqual(c.AdjMatrix, want) {
+		t.Errorf("AdjMatrix = %v, want %v", c.AdjMatrix, want)
+	}
+	if g.NumNodes() != 4 {
+		t.Error("original graph was modified")
+	}
+	if _, err := g.Contract([]string{"A", "Z"}, "AZ"); err == nil {
+		t.Error("expected error for unknown node")
+	}
+	if _, err := g.Contract([]string{"A", "B"}, "X"); err == nil {
+		t.Error("expected error for a name clashing with an external node")
+	}
+}
@@ -0,0 +1,57 @@
+package graph
+
+// Cycles returns up to limit simple directed cycles of g (all of them if limit <= 0), for
+// validating topologies rather than routing. Each cycle lists its nodes once, starting at its
+// lowest-index node; the closing edge back to that node is implied. Cycles are found by DFS
+// from each start node over higher-index nodes only, so each appears exactly once, ordered by
+// start node and then lexicographically by node index.
+func (g *Weighted[W]) Cycles(limit int) [][]string {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	N := len(g.Nodes)
+	var out [][]string
+	onPath := make([]bool, N)
+	var path []int
+	// dfs extends path from u and reports whether the limit was reached.
+	var dfs func(start, u int) bool
+	dfs = func(start, u int) bool {
+		for v := start; v < N; v++ {
+			if g.AdjMatrix[u][v] == 0 {
+				continue
+			}
+			if v == start {
+				cycle := make([]string, len(path))
+				for k, idx := range path {
+					cycle[k] = g.Nodes[idx]
+				}
+				out = append(out, cycle)
+				if limit > 0 && len(out) >= limit {
+					return true
+				}
+				continue
+			}
+			if onPath[v] {
+				continue
+			}
+			onPath[v] = true
+			path = append(path, v)
+			done := dfs(start, v)
+			path = path[:len(path)-1]
+			onPath[v] = false
+			if done {
+				return true
+			}
+		}
+		return false
+	}
+	for start := 0; start < N; start++ {
+		onPath[start] = true
+		path = append(path[:0], start)
+		done := dfs(start, start)
+		onPath[start] = false
+		if done {
+			break
+		}
+	}
+	return out
+}
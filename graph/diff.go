@@ -0,0 +1,32 @@
+package graph
+
+// EdgeDiff compares the edges of two graphs by endpoint names. added holds edges only in newG,
+// removed edges only in oldG, and changed edges present in both with a different cost, carrying
+// the new cost. Edges to nodes missing from one graph count as added or removed. added and
+// changed follow newG's row-major order, removed follows oldG's.
+func EdgeDiff(oldG, newG *Graph) (added, removed, changed []Edge) {
+	oldEdges := oldG.ToGraphJSON().Edges
+	newEdges := newG.ToGraphJSON().Edges
+	oldCost := make(map[[2]string]int, len(oldEdges))
+	for _, e := range oldEdges {
+		oldCost[[2]string{e.From, e.To}] = e.Cost
+	}
+	newCost := make(map[[2]string]int, len(newEdges))
+	for _, e := range newEdges {
+		key := [2]string{e.From, e.To}
+		newCost[key] = e.Cost
+		c, ok := oldCost[key]
+		switch {
+		case !ok:
+			added = append(added, Edge{From: e.From, To: e.To, Cost: e.Cost})
+		case c != e.Cost:
+			changed = append(changed, Edge{From: e.From, To: e.To, Cost: e.Cost})
+		}
+	}
+	for _, e := range oldEdges {
+		if _, ok := newCost[[2]string{e.From, e.To}]; !ok {
+			removed = append(removed, Edge{From: e.From, To: e.To, Cost: e.Cost})
+		}
+	}
+	return added, removed, changed
+}
@@ -0,0 +1,35 @@
+package graph
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestEdgeDiff(t *testing.T) {
+	oldG, err := NewFromStruct(&GraphJSON{Edges: []Edge{
+		{From: "A", To: "B", Cost: 10},
+		{From: "B", To: "C", Cost: 20},
+		{From: "C", To: "A", Cost: 30},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	newG, err := NewFromStruct(&GraphJSON{Edges: []Edge{
+		{From: "A", To: "B", Cost: 10},
+		{From: "B", To: "C", Cost: 25},
+		{From: "C", To: "D", Cost: 5},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	added, removed, changed := EdgeDiff(oldG, newG)
+	if want := []Edge{{From: "C", To: "D", Cost: 5}}; !reflect.DeepEqual(added, want) {
+		t.Errorf("added = %v, want %v", added, want)
+	}
+	if want := []Edge{{From: "C", To: "A", Cost: 30}}; !reflect.DeepEqual(removed, want) {
+		t.Errorf("removed = %v, want %v", removed, want)
+	}
+	if want := []Edge{{From: "B", To: "C", Cost: 25}}; !reflect.DeepEqual(changed, want) {
+		t.Errorf("changed = %v, want %v", changed, want)
+	}
+}
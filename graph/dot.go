@@ -0,0 +1,36 @@
+package graph
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+)
+
+// WriteDOT writes g in Graphviz DOT format, labelling each edge with its cost. Edges that appear
+// consecutively in any of the highlight paths (node names) are drawn red and bold.
+func WriteDOT(w io.Writer, g *Graph, highlight [][]string) error {
+	hot := make(map[[2]string]bool)
+	for _, p := range highlight {
+		for i := 0; i+1 < len(p); i++ {
+			hot[[2]string{p[i], p[i+1]}] = true
+		}
+	}
+	bw := bufio.NewWriter(w)
+	fmt.Fprintln(bw, "digraph G {")
+	N := g.NumNodes()
+	for i := 0; i < N; i++ {
+		fmt.Fprintf(bw, "  %q;\n", g.Name(i))
+	}
+	for i := 0; i < N; i++ {
+		for _, j := range g.Neighbors(i) {
+			from, to := g.Name(i), g.Name(j)
+			attrs := fmt.Sprintf("label=\"%d\"", g.Cost(i, j))
+			if hot[[2]string{from, to}] {
+				attrs += ", color=red, penwidth=2"
+			}
+			fmt.Fprintf(bw, "  %q -> %q [%s];\n", from, to, attrs)
+		}
+	}
+	fmt.Fprintln(bw, "}")
+	return bw.Flush()
+}
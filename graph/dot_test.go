@@ -0,0 +1,32 @@
+package graph
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestWriteDOT(t *testing.T) {
+	g, _ := NewFromStruct(&GraphJSON{
+		Edges: []Edge{
+			{From: "A", To: "B", Cost: 50},
+			{From: "B", To: "C", Cost: 20},
+			{From: "A", To: "C", Cost: 100},
+		},
+	})
+	var buf bytes.Buffer
+	if err := WriteDOT(&buf, g, [][]string{{"A", "B", "C"}}); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		"digraph G {",
+		`"A" -> "B" [label="50", color=red, penwidth=2];`,
+		`"B" -> "C" [label="20", color=red, penwidth=2];`,
+		`"A" -> "C" [label="100"];`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("DOT output missing %q:\n%s", want, out)
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package graph
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// EdgeListOptions controls ReadEdgeListWithOptions.
+type EdgeListOptions struct {
+	// Delimiter separates the fields of a line, parsed with encoding/csv so node names may be
+	// quoted. 0 auto-detects it from the first data line: the first of ',', ';' and '\t' that
+	// splits it into exactly three fields, else fields are separated by any whitespace.
+	Delimiter rune
+}
+
+// ReadEdgeList parses a plain-text edge list with one "from to cost" triple per line, separated
+// by whitespace, commas, semicolons or tabs (see EdgeListOptions). Blank lines and lines starting
+// with '#' are ignored. The result is meant for NewFromStruct, which performs the usual validation.
+func ReadEdgeList(r io.Reader) (*GraphJSON, error) {
+	return ReadEdgeListWithOptions(r, nil)
+}
+
+// ReadEdgeListWithOptions is ReadEdgeList with the delimiter taken from opts.
+func ReadEdgeListWithOptions(r io.Reader, opts *EdgeListOptions) (*GraphJSON, error) {
+	if opts == nil {
+		opts = &EdgeListOptions{}
+	}
+	type dataLine struct {
+		num  int
+		text string
+	}
+	var lines []dataLine
+	sc := bufio.NewScanner(r)
+	line := 0
+	for sc.Scan() {
+		line++
+		text := strings.TrimSpace(sc.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		lines = append(lines, dataLine{line, text})
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	delim := opts.Delimiter
+	if delim == 0 && len(lines) > 0 {
+		delim = detectDelimiter(lines[0].text)
+	}
+	gj := &GraphJSON{}
+	for _, l := range lines {
+		fields := strings.Fields(l.text)
+		if delim != 0 {
+			var err error
+			if fields, err = splitDelimited(l.text, delim); err != nil {
+				return nil, fmt.Errorf("line %d: %w", l.num, err)
+			}
+		}
+		if len(fields) != 3 {
+			return nil, fmt.Errorf("line %d: expected \"from to cost\", got %q", l.num, l.text)
+		}
+		cost, err := strconv.Atoi(fields[2])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid cost %q", l.num, fields[2])
+		}
+		gj.Edges = append(gj.Edges, Edge{From: fields[0], To: fields[1], Cost: cost})
+	}
+	return gj, nil
+}
+
+// detectDelimiter returns the first of ',', ';' and '\t' that splits line into three fields, or
+// 0 for whitespace-separated fields.
+func detectDelimiter(line string) rune {
+	for _, d := range []rune{',', ';', '\t'} {
+		if fields, err := splitDelimited(line, d); err == nil && len(fields) == 3 {
+			return d
+		}
+	}
+	return 0
+}
+
+// splitDelimited parses one line as a CSV record with delimiter delim, trimming each field.
+func splitDelimited(line string, delim rune) ([]string, error) {
+	cr := csv.NewReader(strings.NewReader(line))
+	cr.Comma = delim
+	cr.TrimLeadingSpace = true
+	cr.FieldsPerRecord = -1
+	fields, err := cr.Read()
+	if err != nil {
+		return nil, err
+	}
+	for i := range fields {
+		fields[i] = strings.TrimSpace(fields[i])
+	}
+	return fields, nil
+}
@@ -0,0 +1,49 @@
+package graph
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReadEdgeList(t *testing.T) {
+	in := "# backbone\nA B 50\n\n  B\tC 20  \n# end\n"
+	gj, err := ReadEdgeList(strings.NewReader(in))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(gj.Edges) != 2 || !reflect.DeepEqual(gj.Edges[1], Edge{From: "B", To: "C", Cost: 20}) {
+		t.Errorf("unexpected edges: %v", gj.Edges)
+	}
+	if _, err := ReadEdgeList(strings.NewReader("A B\n")); err == nil {
+		t.Error("expected error for missing cost")
+	}
+	if _, err := ReadEdgeList(strings.NewReader("A B x\n")); err == nil {
+		t.Error("expected error for non-numeric cost")
+	}
+}
+
+func TestReadEdgeList_Delimiters(t *testing.T) {
+	want := []Edge{
+		{From: "A", To: "B, Inc", Cost: 50},
+		{From: "B, Inc", To: "C", Cost: 20},
+	}
+	for name, in := range map[string]string{
+		"comma":     "# export\nA,\"B, Inc\",50\n\"B, Inc\", C, 20\n",
+		"tab":       "A\t\"B, Inc\"\t50\n\"B, Inc\"\tC\t20\n",
+		"semicolon": "A;\"B, Inc\";50\n\n\"B, Inc\";C;20\n",
+	} {
+		gj, err := ReadEdgeList(strings.NewReader(in))
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+			continue
+		}
+		if !reflect.DeepEqual(gj.Edges, want) {
+			t.Errorf("%s: got %v, want %v", name, gj.Edges, want)
+		}
+	}
+	gj, err := ReadEdgeListWithOptions(strings.NewReader("A|B|5\n"), &EdgeListOptions{Delimiter: '|'})
+	if err != nil || !reflect.DeepEqual(gj.Edges, []Edge{{From: "A", To: "B", Cost: 5}}) {
+		t.Errorf("explicit '|': got %v, %v", gj, err)
+	}
+}
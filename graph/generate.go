@@ -0,0 +1,58 @@
+package graph
+
+import (
+	"fmt"
+	"math/rand"
+)
+
+// RandomGraph returns a reproducible random directed graph: nodes "n0".."n<n-1>" and edges
+// distinct, non-self edges (at most n*(n-1)) with costs uniform in [MinCost, maxWeight], where
+// maxWeight is clamped to [MinCost, MaxCost]. The same arguments always yield the same graph.
+// n must be at least 1.
+func RandomGraph(n, edges, maxWeight int, seed int64) *Graph {
+	rng := rand.New(rand.NewSource(seed))
+	maxWeight = min(max(maxWeight, MinCost), MaxCost)
+	edges = min(max(edges, 0), n*(n-1))
+	gj := &GraphJSON{Nodes: make([]string, n)}
+	for i := range gj.Nodes {
+		gj.Nodes[i] = fmt.Sprintf("n%d", i)
+	}
+	seen := make(map[[2]int]bool, edges)
+	for len(gj.Edges) < edges {
+		i, j := rng.Intn(n), rng.Intn(n)
+		if i == j || seen[[2]int{i, j}] {
+			continue
+		}
+		seen[[2]int{i, j}] = true
+		gj.Edges = append(gj.Edges, Edge{From: gj.Nodes[i], To: gj.Nodes[j], Cost: MinCost + rng.Intn(maxWeight-MinCost+1)})
+	}
+	return mustNew(gj)
+}
+
+// GridGraph returns a rows x cols grid with nodes "r<row>c<col>" and cost-1 edges pointing right
+// and down, so every node reaches the bottom-right corner. rows and cols must be at least 1.
+func GridGraph(rows, cols int) *Graph {
+	gj := &GraphJSON{}
+	name := func(r, c int) string { return fmt.Sprintf("r%dc%d", r, c) }
+	for r := 0; r < rows; r++ {
+		for c := 0; c < cols; c++ {
+			gj.Nodes = append(gj.Nodes, name(r, c))
+			if c+1 < cols {
+				gj.Edges = append(gj.Edges, Edge{From: name(r, c), To: name(r, c+1), Cost: 1})
+			}
+			if r+1 < rows {
+				gj.Edges = append(gj.Edges, Edge{From: name(r, c), To: name(r+1, c), Cost: 1})
+			}
+		}
+	}
+	return mustNew(gj)
+}
+
+// mustNew is NewFromStruct for generated input, which is valid by construction.
+func mustNew(gj *GraphJSON) *Graph {
+	g, err := NewFromStruct(gj)
+	if err != nil {
+		panic(err)
+	}
+	return g
+}
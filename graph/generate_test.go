@@ -0,0 +1,42 @@
+package graph
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRandomGraph(t *testing.T) {
+	a := RandomGraph(20, 50, 100, 42)
+	b := RandomGraph(20, 50, 100, 42)
+	if !reflect.DeepEqual(a.AdjMatrix, b.AdjMatrix) || !reflect.DeepEqual(a.Nodes, b.Nodes) {
+		t.Error("same seed produced different graphs")
+	}
+	if c := RandomGraph(20, 50, 100, 43); reflect.DeepEqual(a.AdjMatrix, c.AdjMatrix) {
+		t.Error("different seeds produced the same graph")
+	}
+	edges := 0
+	for i, row := range a.AdjMatrix {
+		for j, w := range row {
+			if w == 0 {
+				continue
+			}
+			edges++
+			if i == j || w < MinCost || w > 100 {
+				t.Errorf("bad edge %d->%d cost %d", i, j, w)
+			}
+		}
+	}
+	if edges != 50 {
+		t.Errorf("got %d edges, want 50", edges)
+	}
+	if got := len(RandomGraph(3, 100, 10, 1).ToGraphJSON().Edges); got != 6 {
+		t.Errorf("edge count should be capped at n*(n-1)=6, got %d", got)
+	}
+}
+
+func TestGridGraph(t *testing.T) {
+	g := GridGraph(2, 3)
+	if g.NumNodes() != 6 || len(g.ToGraphJSON().Edges) != 7 {
+		t.Errorf("2x3 grid: %d nodes, %d edges; want 6, 7", g.NumNodes(), len(g.ToGraphJSON().Edges))
+	}
+}
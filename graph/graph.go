@@ -1,9 +1,14 @@
 package graph
 
 import (
+	"cmp"
 	"encoding/json"
 	"fmt"
+	"math"
 	"os"
+	"path/filepath"
+	"sort"
+	"sync"
 )
 
 const (
@@ -13,20 +18,40 @@ const (
 
 // Edge represents a directed edge in the JSON input.
 type Edge struct {
-	From   string `json:"from"`
-	To     string `json:"to"`
-	Cost   int    `json:"cost"`
-	Type   int    `json:"type"`
-	Status int    `json:"status"` // 0: unknown, 1: normal, 2: blocked
-	Des    string `json:"des"`    // description
+	From     string `json:"from"`
+	To       string `json:"to"`
+	Cost     int    `json:"cost"`
+	Type     int    `json:"type"`
+	Status   int    `json:"status"`             // 0: unknown, 1: normal, 2: blocked
+	Des      string `json:"des"`                // description
+	Capacity int    `json:"capacity,omitempty"` // bandwidth used by WidestPath; 0 means same as Cost
+	// Weights holds additional named metrics (e.g. "latency") for RunFloydBy, each in [MinCost, MaxCost].
+	Weights map[string]int `json:"weights,omitempty"`
+	// OneWay keeps the edge from being mirrored when the graph is built with Options.Undirected.
+	OneWay bool `json:"one_way,omitempty"`
 }
 
 // GraphJSON is the root structure for loading graph from JSON.
 // Nodes are always []string for the algorithm. NewFromJSON accepts files where
 // "nodes" is either ["A","B",...] or [{"nodeId":"A","x":0,"y":0},...].
 type GraphJSON struct {
-	Nodes []string `json:"nodes"`
-	Edges []Edge   `json:"edges"`
+	Nodes []string       `json:"nodes"`
+	Edges []Edge         `json:"edges"`
+	Meta  map[string]any `json:"meta,omitempty"` // opaque metadata, carried through but not used for routing
+	// Aliases maps alternative names (e.g. loopback IPs) to canonical node names; nodes and edge
+	// endpoints given by alias are resolved to the canonical node during construction.
+	Aliases map[string]string `json:"aliases,omitempty"`
+	// NonTransit lists stub nodes that may be a path's source or destination but never an
+	// intermediate hop.
+	NonTransit []string `json:"non_transit,omitempty"`
+	// Attributes holds free-form per-node attributes (site type, region, ASN, ...) keyed by node
+	// name. They are carried alongside routing but do not affect it.
+	Attributes map[string]map[string]string `json:"attributes,omitempty"`
+	// NodeWeight is the cost of passing through a node (e.g. processing delay), keyed by node
+	// name. It is added to the cost of every path for which the node is an intermediate hop;
+	// sources and destinations do not pay it. Weights must be in [0, MaxCost]. The floyd
+	// shortest-path algorithms honor it.
+	NodeWeight map[string]int `json:"node_weight,omitempty"`
 }
 
 // nodeObject is used when parsing "nodes" as array of objects (nodeId, optional x, y).
@@ -39,22 +64,99 @@ type nodeObject struct {
 
 // rawGraphFile is used to parse the JSON file with flexible nodes format.
 type rawGraphFile struct {
-	Nodes json.RawMessage `json:"nodes"`
-	Edges []Edge          `json:"edges"`
+	Nodes      json.RawMessage              `json:"nodes"`
+	Edges      []Edge                       `json:"edges"`
+	Meta       map[string]any               `json:"meta"`
+	Aliases    map[string]string            `json:"aliases"`
+	NonTransit []string                     `json:"non_transit"`
+	Attributes map[string]map[string]string `json:"attributes"`
+	NodeWeight map[string]int               `json:"node_weight"`
 }
 
-// Graph holds nodes and directed edges with costs.
-type Graph struct {
+// Graph is the graph type used throughout pathroute: integer edge costs, built by NewFromStruct
+// and friends from GraphJSON.
+type Graph = Weighted[int]
+
+// Weighted holds nodes and directed edges whose costs have type W. Graph (Weighted[int]) is the
+// common case; other weight types such as Weighted[float64] are built with NewWeighted and share
+// the same methods. Methods that produce GraphJSON or Edge values (ToGraphJSON,
+// MarshalJSONCanonical, SymmetryReport) convert costs to int, truncating any fractional part.
+//
+// Concurrency: a Weighted is safe for concurrent use through its methods. Accessors
+// (NumNodes, Index, Name, Cost, Neighbors, ...) take a read lock and mutators
+// (AddEdge, RemoveEdge) take the write lock, so many readers may query while one
+// writer updates edges. The exported fields are meant for construction and
+// inspection; reading or writing them directly bypasses the lock and is only safe
+// when no mutator can run concurrently.
+type Weighted[W Weight] struct {
+	mu          sync.RWMutex
 	Nodes       []string
 	NameToIndex map[string]int
 	// AdjMatrix[i][j] = cost from node i to j; 0 means no edge (use Inf for unreachable in algo)
-	AdjMatrix [][]int
+	AdjMatrix [][]W
+	// CapMatrix[i][j] = capacity set explicitly for edge i->j (Edge.Capacity); 0 means the
+	// capacity follows the cost. It may be nil, in which case all capacities equal costs.
+	CapMatrix [][]W
+	// Meta is the GraphJSON metadata, kept for round-tripping; it does not affect routing.
+	Meta map[string]any
+	// Metrics[name][i][j] = value of the named extra metric (Edge.Weights) on edge i->j; 0 means
+	// the edge does not carry that metric. Cost itself is AdjMatrix, not stored here.
+	Metrics map[string][][]W
+	// Limits are the bounds the graph was built with; AddEdge checks costs against them. nil means
+	// the defaults (see Limits.Resolved), except for graphs built by NewWeighted, which accept any
+	// positive cost unless Limits is set.
+	Limits *Limits
+	// NonTransit holds the names of nodes that routing must not pass through (see
+	// GraphJSON.NonTransit); use Transit to query it by index.
+	NonTransit map[string]bool
+	// Attributes is GraphJSON.Attributes, keyed by node name; use NodeAttr to query it.
+	Attributes map[string]map[string]string
+	// NodeWeights is GraphJSON.NodeWeight, keyed by node name; use NodeWeight to query it by
+	// index.
+	NodeWeights map[string]W
+	// unbounded is set by NewWeighted: with nil Limits, AddEdge then only requires a positive cost.
+	unbounded bool
 }
 
 // NewFromJSON loads a graph from a JSON file. Costs must be in [MinCost, MaxCost].
 // If nodes is empty, nodes are inferred from edges.
 // The "nodes" field may be either ["A","B",...] or [{"nodeId":"A","x":0,"y":0},...]; x,y are ignored.
 func NewFromJSON(path string) (*Graph, error) {
+	gj, err := readGraphJSON(path)
+	if err != nil {
+		return nil, err
+	}
+	return NewFromStruct(gj)
+}
+
+// NewFromDir loads a graph assembled from every *.json file in dir, each a partial GraphJSON in
+// the NewFromJSON format (typically one node with its outgoing edges). The parts are combined
+// with Merge in file name order, so the same edge with different costs in two files is an error.
+func NewFromDir(dir string) (*Graph, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no *.json files in %s", dir)
+	}
+	parts := make([]*GraphJSON, 0, len(files))
+	for _, f := range files {
+		gj, err := readGraphJSON(f)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", f, err)
+		}
+		parts = append(parts, gj)
+	}
+	gj, err := Merge(parts...)
+	if err != nil {
+		return nil, err
+	}
+	return NewFromStruct(gj)
+}
+
+// readGraphJSON parses the JSON file at path into a GraphJSON, accepting both "nodes" formats.
+func readGraphJSON(path string) (*GraphJSON, error) {
 	data, err := os.ReadFile(path)
 	if err != nil {
 		return nil, err
@@ -67,8 +169,7 @@ func NewFromJSON(path string) (*Graph, error) {
 	if err != nil {
 		return nil, err
 	}
-	gj := &GraphJSON{Nodes: nodeIDs, Edges: raw.Edges}
-	return NewFromStruct(gj)
+	return &GraphJSON{Nodes: nodeIDs, Edges: raw.Edges, Meta: raw.Meta, Aliases: raw.Aliases, NonTransit: raw.NonTransit, Attributes: raw.Attributes, NodeWeight: raw.NodeWeight}, nil
 }
 
 // parseNodeIDs interprets raw (JSON array) as either []string or []nodeObject and returns node ids in order.
@@ -91,17 +192,103 @@ func parseNodeIDs(raw json.RawMessage) ([]string, error) {
 	return ids, nil
 }
 
-// NewFromStruct builds a Graph from GraphJSON. Validates costs in [1, 1000].
+// Options controls graph construction. The zero value (or a nil *Options) gives the default,
+// permissive behavior of NewFromStruct.
+type Options struct {
+	// StrictNodes rejects edges whose endpoints are not listed in GraphJSON.Nodes instead of
+	// inferring those nodes, so a misspelled name is an error rather than a new node.
+	StrictNodes bool
+	// Limits overrides the accepted cost range; nil means [MinCost, MaxCost]. The graph keeps it,
+	// so floyd.RunFloydWithOptions also picks up its path caps unless given its own.
+	Limits *Limits
+	// DuplicateEdges decides what happens when several edges share the same from -> to.
+	DuplicateEdges DuplicateEdgePolicy
+	// MaxNodes and MaxEdges reject larger inputs before any matrix is allocated; 0 means
+	// unlimited. Edges are counted as given, before duplicates are combined.
+	MaxNodes int
+	MaxEdges int
+	// Undirected also adds every edge in the reverse direction, with the same cost, capacity and
+	// weights, unless it is OneWay or that direction is given explicitly.
+	Undirected bool
+	// WeightScale, if non-zero, multiplies every edge cost on load (e.g. 10 to turn milliseconds
+	// into tenths) before it is checked against the cost range. Fractional results are rounded to
+	// the nearest integer, halves away from zero, so a cost of 3 scaled by 0.5 becomes 2. Capacity
+	// and Weights are not scaled. It must not be negative.
+	WeightScale float64
+	// SentinelWeight, if non-zero, is a cost that exports use to mean "no data" (e.g. 999999).
+	// Edges with exactly that cost are dropped on load, before scaling and the cost range check,
+	// instead of becoming links. A node referenced only by such edges is not created unless it is
+	// listed in GraphJSON.Nodes.
+	SentinelWeight int
+}
+
+// DuplicateEdgePolicy is how NewFromStructWithOptions handles repeated from -> to edges.
+type DuplicateEdgePolicy int
+
+const (
+	// KeepLast lets a later edge replace an earlier one (the default).
+	KeepLast DuplicateEdgePolicy = iota
+	// DuplicateError rejects the graph.
+	DuplicateError
+	// KeepSum combines parallel edges into one whose cost and capacity are the sums of theirs;
+	// a summed cost above the maximum cost is an error. Weights are taken from the last edge.
+	KeepSum
+)
+
+// NewFromStruct builds a Graph from GraphJSON. Validates costs in [MinCost, MaxCost].
 func NewFromStruct(gj *GraphJSON) (*Graph, error) {
+	return NewFromStructWithOptions(gj, nil)
+}
+
+// NewFromStructWithOptions builds a Graph from GraphJSON like NewFromStruct, applying opts.
+func NewFromStructWithOptions(gj *GraphJSON, opts *Options) (*Graph, error) {
+	if opts == nil {
+		opts = &Options{}
+	}
+	lim := opts.Limits.Resolved()
+	if opts.MaxEdges > 0 && len(gj.Edges) > opts.MaxEdges {
+		return nil, fmt.Errorf("graph has %d edges, more than the limit of %d", len(gj.Edges), opts.MaxEdges)
+	}
+	gj, err := resolveAliases(gj)
+	if err != nil {
+		return nil, err
+	}
+	if opts.SentinelWeight != 0 {
+		gj = dropSentinelEdges(gj, opts.SentinelWeight)
+	}
+	if opts.WeightScale < 0 {
+		return nil, fmt.Errorf("weight scale %g is negative", opts.WeightScale)
+	}
+	if opts.WeightScale != 0 {
+		gj = scaleCosts(gj, opts.WeightScale)
+	}
 	nodeSet := make(map[string]struct{})
 	for _, n := range gj.Nodes {
 		nodeSet[n] = struct{}{}
 	}
 	for _, e := range gj.Edges {
+		if opts.StrictNodes {
+			for _, n := range []string{e.From, e.To} {
+				if _, ok := nodeSet[n]; !ok {
+					return nil, fmt.Errorf("edge %s -> %s references undeclared node %q", e.From, e.To, n)
+				}
+			}
+		}
 		nodeSet[e.From] = struct{}{}
 		nodeSet[e.To] = struct{}{}
-		if e.Cost < MinCost || e.Cost > MaxCost {
-			return nil, fmt.Errorf("edge %s -> %s cost %d out of range [%d, %d]", e.From, e.To, e.Cost, MinCost, MaxCost)
+		if e.Cost < lim.MinCost || e.Cost > lim.MaxCost {
+			return nil, fmt.Errorf("edge %s -> %s cost %d out of range [%d, %d]", e.From, e.To, e.Cost, lim.MinCost, lim.MaxCost)
+		}
+		if e.Capacity < 0 {
+			return nil, fmt.Errorf("edge %s -> %s capacity %d is negative", e.From, e.To, e.Capacity)
+		}
+		for name, w := range e.Weights {
+			if name == CostMetric {
+				return nil, fmt.Errorf("edge %s -> %s: metric %q is reserved for cost", e.From, e.To, name)
+			}
+			if w < lim.MinCost || w > lim.MaxCost {
+				return nil, fmt.Errorf("edge %s -> %s %s %d out of range [%d, %d]", e.From, e.To, name, w, lim.MinCost, lim.MaxCost)
+			}
 		}
 	}
 	// stable order: first from Nodes, then any from edges
@@ -124,43 +311,324 @@ func NewFromStruct(gj *GraphJSON) (*Graph, error) {
 	if len(nodes) == 0 {
 		return nil, fmt.Errorf("graph has no nodes")
 	}
+	if opts.MaxNodes > 0 && len(nodes) > opts.MaxNodes {
+		return nil, fmt.Errorf("graph has %d nodes, more than the limit of %d", len(nodes), opts.MaxNodes)
+	}
 	nameToIndex := make(map[string]int)
 	for i, n := range nodes {
 		nameToIndex[n] = i
 	}
-	N := len(nodes)
-	adj := make([][]int, N)
-	for i := range adj {
-		adj[i] = make([]int, N)
+	var nonTransit map[string]bool
+	for _, n := range gj.NonTransit {
+		if _, ok := nameToIndex[n]; !ok {
+			return nil, fmt.Errorf("non-transit node %q is not in the graph", n)
+		}
+		if nonTransit == nil {
+			nonTransit = make(map[string]bool)
+		}
+		nonTransit[n] = true
 	}
-	for _, e := range gj.Edges {
+	for n := range gj.Attributes {
+		if _, ok := nameToIndex[n]; !ok {
+			return nil, fmt.Errorf("attributes given for unknown node %q", n)
+		}
+	}
+	for n, w := range gj.NodeWeight {
+		if _, ok := nameToIndex[n]; !ok {
+			return nil, fmt.Errorf("node weight given for unknown node %q", n)
+		}
+		if w < 0 || w > lim.MaxCost {
+			return nil, fmt.Errorf("node %q weight %d out of range [0, %d]", n, w, lim.MaxCost)
+		}
+	}
+	edges := gj.Edges
+	if opts.Undirected {
+		edges = mirrorEdges(edges)
+	}
+	N := len(nodes)
+	adj := newMatrix[int](N)
+	capm := newMatrix[int](N)
+	var metrics map[string][][]int
+	for _, e := range edges {
 		from, to := nameToIndex[e.From], nameToIndex[e.To]
-		adj[from][to] = e.Cost
+		cost, capacity := e.Cost, e.Capacity
+		if adj[from][to] != 0 {
+			switch opts.DuplicateEdges {
+			case DuplicateError:
+				return nil, fmt.Errorf("duplicate edge %s -> %s", e.From, e.To)
+			case KeepSum:
+				// The sum stays implicit only if neither capacity was given.
+				if capacity != 0 || capm[from][to] != 0 {
+					capacity = cmp.Or(capacity, e.Cost) + cmp.Or(capm[from][to], adj[from][to])
+				}
+				cost += adj[from][to]
+				if cost > lim.MaxCost {
+					return nil, fmt.Errorf("edge %s -> %s summed cost %d exceeds %d", e.From, e.To, cost, lim.MaxCost)
+				}
+			}
+		}
+		adj[from][to] = cost
+		capm[from][to] = capacity
+		for name, w := range e.Weights {
+			if metrics == nil {
+				metrics = make(map[string][][]int)
+			}
+			if metrics[name] == nil {
+				metrics[name] = newMatrix[int](N)
+			}
+			metrics[name][from][to] = w
+		}
 	}
 	return &Graph{
 		Nodes:       nodes,
 		NameToIndex: nameToIndex,
 		AdjMatrix:   adj,
+		CapMatrix:   capm,
+		Meta:        gj.Meta,
+		Metrics:     metrics,
+		Limits:      opts.Limits,
+		NonTransit:  nonTransit,
+		Attributes:  copyAttributes(gj.Attributes, nameToIndex),
+		NodeWeights: copyNodeWeights(gj.NodeWeight, nameToIndex),
 	}, nil
 }
 
+// dropSentinelEdges returns a copy of gj without the edges whose cost is sentinel.
+func dropSentinelEdges(gj *GraphJSON, sentinel int) *GraphJSON {
+	out := *gj
+	out.Edges = make([]Edge, 0, len(gj.Edges))
+	for _, e := range gj.Edges {
+		if e.Cost != sentinel {
+			out.Edges = append(out.Edges, e)
+		}
+	}
+	return &out
+}
+
+// scaleCosts returns a copy of gj with every edge cost multiplied by scale and rounded.
+func scaleCosts(gj *GraphJSON, scale float64) *GraphJSON {
+	out := *gj
+	out.Edges = make([]Edge, len(gj.Edges))
+	for i, e := range gj.Edges {
+		e.Cost = int(math.Round(float64(e.Cost) * scale))
+		out.Edges[i] = e
+	}
+	return &out
+}
+
+// mirrorEdges returns edges followed by the reverse of each edge that is not OneWay and whose
+// reverse direction is not among edges.
+func mirrorEdges(edges []Edge) []Edge {
+	given := make(map[[2]string]bool, len(edges))
+	for _, e := range edges {
+		given[[2]string{e.From, e.To}] = true
+	}
+	out := append([]Edge(nil), edges...)
+	for _, e := range edges {
+		if e.OneWay || given[[2]string{e.To, e.From}] {
+			continue
+		}
+		r := e
+		r.From, r.To = e.To, e.From
+		out = append(out, r)
+	}
+	return out
+}
+
+// resolveAliases returns gj with every aliased node name replaced by its canonical name. Each
+// canonical name must be a node of the resolved graph and must not itself be an alias.
+func resolveAliases(gj *GraphJSON) (*GraphJSON, error) {
+	if len(gj.Aliases) == 0 {
+		return gj, nil
+	}
+	resolve := func(n string) string {
+		if c, ok := gj.Aliases[n]; ok {
+			return c
+		}
+		return n
+	}
+	out := *gj
+	out.Nodes = make([]string, len(gj.Nodes))
+	known := make(map[string]bool)
+	for i, n := range gj.Nodes {
+		out.Nodes[i] = resolve(n)
+		known[out.Nodes[i]] = true
+	}
+	out.Edges = make([]Edge, len(gj.Edges))
+	for i, e := range gj.Edges {
+		e.From, e.To = resolve(e.From), resolve(e.To)
+		out.Edges[i] = e
+		known[e.From], known[e.To] = true, true
+	}
+	out.NonTransit = make([]string, len(gj.NonTransit))
+	for i, n := range gj.NonTransit {
+		out.NonTransit[i] = resolve(n)
+	}
+	if gj.Attributes != nil {
+		out.Attributes = make(map[string]map[string]string, len(gj.Attributes))
+		for n, attrs := range gj.Attributes {
+			c := resolve(n)
+			if out.Attributes[c] == nil {
+				out.Attributes[c] = make(map[string]string, len(attrs))
+			}
+			for k, v := range attrs {
+				if prev, ok := out.Attributes[c][k]; ok && prev != v {
+					return nil, fmt.Errorf("node %q attribute %q given as both %q and %q", c, k, prev, v)
+				}
+				out.Attributes[c][k] = v
+			}
+		}
+	}
+	if gj.NodeWeight != nil {
+		out.NodeWeight = make(map[string]int, len(gj.NodeWeight))
+		for n, w := range gj.NodeWeight {
+			c := resolve(n)
+			if prev, ok := out.NodeWeight[c]; ok && prev != w {
+				return nil, fmt.Errorf("node %q weight given as both %d and %d", c, prev, w)
+			}
+			out.NodeWeight[c] = w
+		}
+	}
+	for alias, canonical := range gj.Aliases {
+		if _, ok := gj.Aliases[canonical]; ok && canonical != alias {
+			return nil, fmt.Errorf("alias %q -> %q: canonical name is itself an alias", alias, canonical)
+		}
+		if !known[canonical] {
+			return nil, fmt.Errorf("alias %q -> unknown node %q", alias, canonical)
+		}
+	}
+	return &out, nil
+}
+
+// copyNonTransit returns a copy of g.NonTransit, or nil if it is empty.
+func (g *Weighted[W]) copyNonTransit() map[string]bool {
+	if len(g.NonTransit) == 0 {
+		return nil
+	}
+	m := make(map[string]bool, len(g.NonTransit))
+	for n, v := range g.NonTransit {
+		m[n] = v
+	}
+	return m
+}
+
+// copyAttributes returns a deep copy of attrs restricted to the names in nameToIndex, or nil if
+// nothing remains.
+func copyAttributes(attrs map[string]map[string]string, nameToIndex map[string]int) map[string]map[string]string {
+	var out map[string]map[string]string
+	for n, a := range attrs {
+		if _, ok := nameToIndex[n]; !ok {
+			continue
+		}
+		if out == nil {
+			out = make(map[string]map[string]string, len(attrs))
+		}
+		m := make(map[string]string, len(a))
+		for k, v := range a {
+			m[k] = v
+		}
+		out[n] = m
+	}
+	return out
+}
+
+// copyNodeWeights returns a copy of weights restricted to the names in nameToIndex, or nil if
+// nothing remains.
+func copyNodeWeights[W Weight](weights map[string]W, nameToIndex map[string]int) map[string]W {
+	var out map[string]W
+	for n, w := range weights {
+		if _, ok := nameToIndex[n]; !ok {
+			continue
+		}
+		if out == nil {
+			out = make(map[string]W, len(weights))
+		}
+		out[n] = w
+	}
+	return out
+}
+
+// NodeWeight returns the cost of passing through node i (0 if it has none).
+func (g *Weighted[W]) NodeWeight(i int) W {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return g.NodeWeights[g.Nodes[i]]
+}
+
+// NodeAttr returns the value of node name's attribute key; ok is false if the node or the
+// attribute is not set.
+func (g *Weighted[W]) NodeAttr(name, key string) (string, bool) {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	v, ok := g.Attributes[name][key]
+	return v, ok
+}
+
+// Transit reports whether paths may pass through node i, i.e. it is not a non-transit node.
+func (g *Weighted[W]) Transit(i int) bool {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return !g.NonTransit[g.Nodes[i]]
+}
+
 // NumNodes returns the number of nodes.
-func (g *Graph) NumNodes() int { return len(g.Nodes) }
+func (g *Weighted[W]) NumNodes() int {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return len(g.Nodes)
+}
 
 // Index returns node index by name; ok is false if name not found.
-func (g *Graph) Index(name string) (int, bool) {
+func (g *Weighted[W]) Index(name string) (int, bool) {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
 	i, ok := g.NameToIndex[name]
 	return i, ok
 }
 
+// SortedNodes returns a lexicographically sorted copy of the node names.
+func (g *Weighted[W]) SortedNodes() []string {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	out := make([]string, len(g.Nodes))
+	copy(out, g.Nodes)
+	sort.Strings(out)
+	return out
+}
+
 // Name returns node name by index.
-func (g *Graph) Name(i int) string { return g.Nodes[i] }
+func (g *Weighted[W]) Name(i int) string {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return g.Nodes[i]
+}
 
 // Cost returns the cost of edge from i to j; 0 means no edge.
-func (g *Graph) Cost(i, j int) int { return g.AdjMatrix[i][j] }
+func (g *Weighted[W]) Cost(i, j int) W {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return g.AdjMatrix[i][j]
+}
+
+// CostMatrix returns a copy of the adjacency matrix taken under a single read lock, for hot
+// loops where calling Cost per entry would be too costly.
+func (g *Weighted[W]) CostMatrix() [][]W {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return copyMatrix(g.AdjMatrix)
+}
+
+// Capacity returns the capacity of edge from i to j; 0 means no edge.
+func (g *Weighted[W]) Capacity(i, j int) W {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return g.capacity(i, j)
+}
 
 // Neighbors returns out-neighbors of node index i (nodes j such that edge i->j exists).
-func (g *Graph) Neighbors(i int) []int {
+func (g *Weighted[W]) Neighbors(i int) []int {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
 	var out []int
 	for j := 0; j < len(g.AdjMatrix[i]); j++ {
 		if g.AdjMatrix[i][j] > 0 {
@@ -170,12 +638,112 @@ func (g *Graph) Neighbors(i int) []int {
 	return out
 }
 
+// AddEdge sets the directed edge from -> to to cost. An existing edge keeps its metric values and
+// any explicitly set capacity; otherwise its capacity follows the new cost, as does a new edge's.
+// Both nodes must already exist and cost must be within g.Limits. Adding a new edge to a graph
+// that carries metrics (Edge.Weights) is an error, since the edge would be invisible when routing
+// by them; use AddEdgeWeights instead.
+func (g *Weighted[W]) AddEdge(from, to string, cost W) error {
+	return g.AddEdgeWeights(from, to, cost, nil)
+}
+
+// AddEdgeWeights is AddEdge that also sets the edge's value of each metric in weights, which
+// must be metrics g already carries, with values in the same range as cost. A new edge needs a
+// value for every metric of g; an existing edge keeps the values of metrics not in weights.
+func (g *Weighted[W]) AddEdgeWeights(from, to string, cost W, weights map[string]W) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if err := g.checkCost(from, to, "cost", cost); err != nil {
+		return err
+	}
+	i, j, err := g.edgeIndices(from, to)
+	if err != nil {
+		return err
+	}
+	for name, w := range weights {
+		if _, ok := g.Metrics[name]; !ok {
+			return fmt.Errorf("edge %s -> %s: unknown metric %q", from, to, name)
+		}
+		if err := g.checkCost(from, to, name, w); err != nil {
+			return err
+		}
+	}
+	if g.AdjMatrix[i][j] == 0 {
+		for name := range g.Metrics {
+			if _, ok := weights[name]; !ok {
+				return fmt.Errorf("new edge %s -> %s needs a value for metric %q", from, to, name)
+			}
+		}
+		if g.CapMatrix != nil {
+			g.CapMatrix[i][j] = 0
+		}
+	}
+	g.AdjMatrix[i][j] = cost
+	for name, w := range weights {
+		g.Metrics[name][i][j] = w
+	}
+	return nil
+}
+
+// checkCost checks value, the cost or the named metric of edge from -> to, against g's range.
+func (g *Weighted[W]) checkCost(from, to, what string, value W) error {
+	if g.unbounded && g.Limits == nil {
+		if !(value > 0) {
+			return fmt.Errorf("edge %s -> %s %s %v must be positive", from, to, what, value)
+		}
+	} else if lim := g.Limits.Resolved(); value < W(lim.MinCost) || value > W(lim.MaxCost) {
+		return fmt.Errorf("edge %s -> %s %s %v out of range [%d, %d]", from, to, what, value, lim.MinCost, lim.MaxCost)
+	}
+	return nil
+}
+
+// RemoveEdge deletes the directed edge from -> to, with its capacity and metric values. It is an
+// error if the edge does not exist.
+func (g *Weighted[W]) RemoveEdge(from, to string) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	i, j, err := g.edgeIndices(from, to)
+	if err != nil {
+		return err
+	}
+	if g.AdjMatrix[i][j] == 0 {
+		return fmt.Errorf("edge %s -> %s not found", from, to)
+	}
+	g.AdjMatrix[i][j] = 0
+	if g.CapMatrix != nil {
+		g.CapMatrix[i][j] = 0
+	}
+	for _, m := range g.Metrics {
+		m[i][j] = 0
+	}
+	return nil
+}
+
+// edgeIndices resolves both endpoint names; the caller must hold g.mu.
+func (g *Weighted[W]) edgeIndices(from, to string) (int, int, error) {
+	i, ok := g.NameToIndex[from]
+	if !ok {
+		return 0, 0, fmt.Errorf("unknown node %q", from)
+	}
+	j, ok := g.NameToIndex[to]
+	if !ok {
+		return 0, 0, fmt.Errorf("unknown node %q", to)
+	}
+	if i == j {
+		return 0, 0, fmt.Errorf("edge %s -> %s: from and to must differ", from, to)
+	}
+	return i, j, nil
+}
+
 // CopyWithoutNode returns a new graph with the same nodes and edges, but with node excludeIdx
 // removed (smaller node set and reindexed). Used for G\S when computing via-neighbor paths.
 // It also returns the new index mapping: newIndex[oldIndex] = new index, or -1 if excluded.
-func (g *Graph) CopyWithoutNode(excludeIdx int) (*Graph, []int) {
-	oldN := g.NumNodes()
-	newNodes := make([]string, 0, oldN-1)
+// Removing the only node yields a graph with no nodes; an out-of-range excludeIdx removes nothing.
+func (g *Weighted[W]) CopyWithoutNode(excludeIdx int) (*Weighted[W], []int) {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	oldN := len(g.Nodes)
+	newNodes := make([]string, 0, oldN)
 	oldToNew := make([]int, oldN)
 	for i := 0; i < oldN; i++ {
 		if i == excludeIdx {
@@ -186,10 +754,8 @@ func (g *Graph) CopyWithoutNode(excludeIdx int) (*Graph, []int) {
 		newNodes = append(newNodes, g.Nodes[i])
 	}
 	N := len(newNodes)
-	adj := make([][]int, N)
-	for i := range adj {
-		adj[i] = make([]int, N)
-	}
+	adj := newMatrix[W](N)
+	capm := newMatrix[W](N)
 	for i := 0; i < oldN; i++ {
 		if i == excludeIdx {
 			continue
@@ -201,15 +767,393 @@ func (g *Graph) CopyWithoutNode(excludeIdx int) (*Graph, []int) {
 			}
 			nj := oldToNew[j]
 			adj[ni][nj] = g.AdjMatrix[i][j]
+			capm[ni][nj] = g.explicitCapacity(i, j)
 		}
 	}
 	nameToIndex := make(map[string]int)
 	for i, n := range newNodes {
 		nameToIndex[n] = i
 	}
-	return &Graph{
+	return &Weighted[W]{
 		Nodes:       newNodes,
 		NameToIndex: nameToIndex,
 		AdjMatrix:   adj,
+		CapMatrix:   capm,
+		Limits:      g.Limits,
+		NonTransit:  g.copyNonTransit(),
+		Attributes:  copyAttributes(g.Attributes, nameToIndex),
+		NodeWeights: copyNodeWeights(g.NodeWeights, nameToIndex),
+		unbounded:   g.unbounded,
 	}, oldToNew
 }
+
+// Reverse returns a new graph with the same nodes in the same order and every
+// edge i->j replaced by j->i with the same cost.
+func (g *Weighted[W]) Reverse() *Weighted[W] {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	N := len(g.Nodes)
+	nodes := make([]string, N)
+	copy(nodes, g.Nodes)
+	adj := newMatrix[W](N)
+	capm := newMatrix[W](N)
+	for i := 0; i < N; i++ {
+		for j := 0; j < N; j++ {
+			adj[j][i] = g.AdjMatrix[i][j]
+			capm[j][i] = g.explicitCapacity(i, j)
+		}
+	}
+	var metrics map[string][][]W
+	for name, m := range g.Metrics {
+		if metrics == nil {
+			metrics = make(map[string][][]W, len(g.Metrics))
+		}
+		t := newMatrix[W](N)
+		for i := 0; i < N; i++ {
+			for j := 0; j < N; j++ {
+				t[j][i] = m[i][j]
+			}
+		}
+		metrics[name] = t
+	}
+	nameToIndex := make(map[string]int)
+	for i, n := range nodes {
+		nameToIndex[n] = i
+	}
+	return &Weighted[W]{
+		Nodes:       nodes,
+		NameToIndex: nameToIndex,
+		AdjMatrix:   adj,
+		CapMatrix:   capm,
+		Metrics:     metrics,
+		Limits:      g.Limits,
+		NonTransit:  g.copyNonTransit(),
+		Attributes:  copyAttributes(g.Attributes, nameToIndex),
+		NodeWeights: copyNodeWeights(g.NodeWeights, nameToIndex),
+		unbounded:   g.unbounded,
+	}
+}
+
+// Clone returns a deep copy of g that can be mutated independently.
+func (g *Weighted[W]) Clone() *Weighted[W] {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	nodes := make([]string, len(g.Nodes))
+	copy(nodes, g.Nodes)
+	adj := copyMatrix(g.AdjMatrix)
+	capm := copyMatrix(g.CapMatrix)
+	var meta map[string]any
+	if g.Meta != nil {
+		meta = make(map[string]any, len(g.Meta))
+		for k, v := range g.Meta {
+			meta[k] = v
+		}
+	}
+	var metrics map[string][][]W
+	for name, m := range g.Metrics {
+		if metrics == nil {
+			metrics = make(map[string][][]W, len(g.Metrics))
+		}
+		metrics[name] = copyMatrix(m)
+	}
+	nameToIndex := make(map[string]int, len(g.NameToIndex))
+	for n, i := range g.NameToIndex {
+		nameToIndex[n] = i
+	}
+	return &Weighted[W]{
+		Nodes:       nodes,
+		NameToIndex: nameToIndex,
+		AdjMatrix:   adj,
+		CapMatrix:   capm,
+		Meta:        meta,
+		Metrics:     metrics,
+		Limits:      g.Limits,
+		NonTransit:  g.copyNonTransit(),
+		Attributes:  copyAttributes(g.Attributes, nameToIndex),
+		NodeWeights: copyNodeWeights(g.NodeWeights, nameToIndex),
+		unbounded:   g.unbounded,
+	}
+}
+
+// ToGraphJSON converts g back to its JSON form: all nodes in index order, edges in row-major
+// order, the metadata, non-transit nodes and node attributes. Capacity is only set on edges whose
+// capacity differs from the cost; extra metrics are carried in Weights.
+// Edge Type, Status and Des are not stored on Graph and are therefore zero.
+func (g *Weighted[W]) ToGraphJSON() *GraphJSON {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	gj := &GraphJSON{
+		Nodes: make([]string, len(g.Nodes)),
+		Meta:  g.Meta,
+	}
+	copy(gj.Nodes, g.Nodes)
+	for _, n := range g.Nodes {
+		if g.NonTransit[n] {
+			gj.NonTransit = append(gj.NonTransit, n)
+		}
+	}
+	gj.Attributes = copyAttributes(g.Attributes, g.NameToIndex)
+	for n, w := range g.NodeWeights {
+		if gj.NodeWeight == nil {
+			gj.NodeWeight = make(map[string]int, len(g.NodeWeights))
+		}
+		gj.NodeWeight[n] = int(w)
+	}
+	for i := range g.AdjMatrix {
+		for j, w := range g.AdjMatrix[i] {
+			if w == 0 {
+				continue
+			}
+			e := Edge{From: g.Nodes[i], To: g.Nodes[j], Cost: int(w)}
+			if c := g.capacity(i, j); c != w {
+				e.Capacity = int(c)
+			}
+			for name, m := range g.Metrics {
+				if m[i][j] == 0 {
+					continue
+				}
+				if e.Weights == nil {
+					e.Weights = make(map[string]int)
+				}
+				e.Weights[name] = int(m[i][j])
+			}
+			gj.Edges = append(gj.Edges, e)
+		}
+	}
+	return gj
+}
+
+// MarshalJSONCanonical returns g's JSON form (see ToGraphJSON) in a layout that only changes
+// when the graph does, for committing graphs to version control: nodes and non-transit nodes
+// sorted by name, edges sorted by (from, to), map keys sorted, indented, with a final newline.
+func (g *Weighted[W]) MarshalJSONCanonical() ([]byte, error) {
+	gj := g.ToGraphJSON()
+	sort.Strings(gj.Nodes)
+	sort.Strings(gj.NonTransit)
+	sort.Slice(gj.Edges, func(a, b int) bool {
+		ea, eb := gj.Edges[a], gj.Edges[b]
+		if ea.From != eb.From {
+			return ea.From < eb.From
+		}
+		return ea.To < eb.To
+	})
+	data, err := json.MarshalIndent(gj, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+// capacity is Capacity without locking; the caller must hold g.mu.
+func (g *Weighted[W]) capacity(i, j int) W {
+	if c := g.explicitCapacity(i, j); c != 0 {
+		return c
+	}
+	return g.AdjMatrix[i][j]
+}
+
+// explicitCapacity returns the capacity set explicitly for edge i->j, or 0 if it follows the cost.
+// The caller must hold g.mu.
+func (g *Weighted[W]) explicitCapacity(i, j int) W {
+	if g.CapMatrix == nil {
+		return 0
+	}
+	return g.CapMatrix[i][j]
+}
+
+// newMatrix returns an n x n matrix of zeros.
+func newMatrix[W Weight](n int) [][]W {
+	m := make([][]W, n)
+	for i := range m {
+		m[i] = make([]W, n)
+	}
+	return m
+}
+
+// copyMatrix returns a deep copy of m, or nil if m is nil.
+func copyMatrix[W Weight](m [][]W) [][]W {
+	if m == nil {
+		return nil
+	}
+	out := make([][]W, len(m))
+	for i, row := range m {
+		out[i] = make([]W, len(row))
+		copy(out[i], row)
+	}
+	return out
+}
+
+// ReachableWithin returns the nodes reachable from from over at most maxHops edges, regardless of
+// cost, ordered by hop count and then node index; from itself comes first, at hop 0. Non-transit
+// nodes are not treated specially, since this describes the topology rather than routing.
+func (g *Weighted[W]) ReachableWithin(from string, maxHops int) ([]string, error) {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	src, ok := g.NameToIndex[from]
+	if !ok {
+		return nil, fmt.Errorf("unknown node %q", from)
+	}
+	if maxHops < 0 {
+		return nil, fmt.Errorf("maxHops %d is negative", maxHops)
+	}
+	seen := make([]bool, len(g.Nodes))
+	seen[src] = true
+	out := []string{from}
+	frontier := []int{src}
+	for hop := 0; hop < maxHops && len(frontier) > 0; hop++ {
+		var next []int
+		for _, u := range frontier {
+			for v, w := range g.AdjMatrix[u] {
+				if w != 0 && !seen[v] {
+					seen[v] = true
+					next = append(next, v)
+				}
+			}
+		}
+		sort.Ints(next)
+		for _, v := range next {
+			out = append(out, g.Nodes[v])
+		}
+		frontier = next
+	}
+	return out, nil
+}
+
+// IsolatedSources returns, in index order, the nodes with no outgoing edges: every pair
+// starting at such a node is unreachable.
+func (g *Weighted[W]) IsolatedSources() []string {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	var out []string
+	for i, n := range g.Nodes {
+		if !hasNonZero(g.AdjMatrix[i]) {
+			out = append(out, n)
+		}
+	}
+	return out
+}
+
+// IsolatedSinks returns, in index order, the nodes with no incoming edges: every pair
+// ending at such a node is unreachable.
+func (g *Weighted[W]) IsolatedSinks() []string {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	var out []string
+	for j, n := range g.Nodes {
+		in := false
+		for i := range g.AdjMatrix {
+			if g.AdjMatrix[i][j] > 0 {
+				in = true
+				break
+			}
+		}
+		if !in {
+			out = append(out, n)
+		}
+	}
+	return out
+}
+
+// WeightHistogram counts edges by cost in buckets of bucketSize, keyed by each bucket's lower
+// bound. Buckets start at the graph's minimum cost (g.Limits), so with the defaults and
+// bucketSize 100 the keys are 1, 101, ..., 901. It returns nil if bucketSize <= 0.
+func (g *Weighted[W]) WeightHistogram(bucketSize int) map[int]int {
+	if bucketSize <= 0 {
+		return nil
+	}
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	lo := g.Limits.Resolved().MinCost
+	hist := make(map[int]int)
+	for _, row := range g.AdjMatrix {
+		for _, w := range row {
+			if w != 0 {
+				hist[lo+(int(w)-lo)/bucketSize*bucketSize]++
+			}
+		}
+	}
+	return hist
+}
+
+// WeightRangeWarnings reports, for review, where the ratio of the largest to the smallest edge
+// cost exceeds ratio: first over the whole graph, then among each node's outgoing edges (in
+// node order). It is advisory; nil means no warnings.
+func (g *Weighted[W]) WeightRangeWarnings(ratio float64) []string {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	var out []string
+	var lo, hi W
+	for i, row := range g.AdjMatrix {
+		var rlo, rhi W
+		rloTo, rhiTo := 0, 0
+		for j, w := range row {
+			if w == 0 {
+				continue
+			}
+			if rlo == 0 || w < rlo {
+				rlo, rloTo = w, j
+			}
+			if w > rhi {
+				rhi, rhiTo = w, j
+			}
+		}
+		if rlo != 0 && float64(rhi)/float64(rlo) > ratio {
+			out = append(out, fmt.Sprintf("node %s: outgoing costs range from %v (to %s) to %v (to %s), ratio %.4g > %.4g",
+				g.Nodes[i], rlo, g.Nodes[rloTo], rhi, g.Nodes[rhiTo], float64(rhi)/float64(rlo), ratio))
+		}
+		if rlo != 0 && (lo == 0 || rlo < lo) {
+			lo = rlo
+		}
+		hi = max(hi, rhi)
+	}
+	if lo != 0 && float64(hi)/float64(lo) > ratio {
+		global := fmt.Sprintf("graph: edge costs range from %v to %v, ratio %.4g > %.4g", lo, hi, float64(hi)/float64(lo), ratio)
+		out = append([]string{global}, out...)
+	}
+	return out
+}
+
+func hasNonZero[W Weight](row []W) bool {
+	for _, w := range row {
+		if w > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate checks the internal consistency of g, which matters for graphs built by hand rather
+// than by NewFromStruct: AdjMatrix (and CapMatrix, if set) must be NumNodes() x NumNodes(), and
+// NameToIndex must map exactly each Nodes[i] to i.
+func (g *Weighted[W]) Validate() error {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	N := len(g.Nodes)
+	if len(g.AdjMatrix) != N {
+		return fmt.Errorf("AdjMatrix has %d rows, want %d (number of nodes)", len(g.AdjMatrix), N)
+	}
+	for i, row := range g.AdjMatrix {
+		if len(row) != N {
+			return fmt.Errorf("AdjMatrix row %d (%s) has length %d, want %d", i, g.Nodes[i], len(row), N)
+		}
+	}
+	if g.CapMatrix != nil {
+		if len(g.CapMatrix) != N {
+			return fmt.Errorf("CapMatrix has %d rows, want %d (number of nodes)", len(g.CapMatrix), N)
+		}
+		for i, row := range g.CapMatrix {
+			if len(row) != N {
+				return fmt.Errorf("CapMatrix row %d (%s) has length %d, want %d", i, g.Nodes[i], len(row), N)
+			}
+		}
+	}
+	if len(g.NameToIndex) != N {
+		return fmt.Errorf("NameToIndex has %d entries, want %d", len(g.NameToIndex), N)
+	}
+	for i, n := range g.Nodes {
+		if idx, ok := g.NameToIndex[n]; !ok || idx != i {
+			return fmt.Errorf("NameToIndex[%q] = %d (present %v), want %d", n, idx, ok, i)
+		}
+	}
+	return nil
+}
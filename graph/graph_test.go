@@ -1,9 +1,12 @@
 package graph
 
 import (
+	"bytes"
 	"encoding/json"
 	"os"
 	"path/filepath"
+	"reflect"
+	"sync"
 	"testing"
 )
 
@@ -164,3 +167,755 @@ func TestGraphJSON_Roundtrip(t *testing.T) {
 		t.Errorf("roundtrip cost: got %d", g.Cost(0, 1))
 	}
 }
+
+func TestAddRemoveEdge(t *testing.T) {
+	g, err := NewFromStruct(&GraphJSON{Nodes: []string{"A", "B"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := g.AddEdge("A", "B", 30); err != nil {
+		t.Fatal(err)
+	}
+	if g.Cost(0, 1) != 30 {
+		t.Errorf("A->B after AddEdge: got %d", g.Cost(0, 1))
+	}
+	if err := g.AddEdge("A", "X", 30); err == nil {
+		t.Error("expected error for unknown node")
+	}
+	if err := g.AddEdge("A", "B", MaxCost+1); err == nil {
+		t.Error("expected error for cost out of range")
+	}
+	if err := g.RemoveEdge("A", "B"); err != nil {
+		t.Fatal(err)
+	}
+	if g.Cost(0, 1) != 0 {
+		t.Errorf("A->B after RemoveEdge: got %d", g.Cost(0, 1))
+	}
+	if err := g.RemoveEdge("A", "B"); err == nil {
+		t.Error("expected error removing missing edge")
+	}
+}
+
+func TestAddEdge_KeepsCapacity(t *testing.T) {
+	g, err := NewFromStruct(&GraphJSON{Edges: []Edge{{From: "A", To: "B", Cost: 10, Capacity: 5}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := g.AddEdge("A", "B", 30); err != nil {
+		t.Fatal(err)
+	}
+	if g.Cost(0, 1) != 30 || g.Capacity(0, 1) != 5 {
+		t.Errorf("A->B = cost %d capacity %d, want 30 and the original 5", g.Cost(0, 1), g.Capacity(0, 1))
+	}
+	if err := g.AddEdge("B", "A", 7); err != nil {
+		t.Fatal(err)
+	}
+	if g.Capacity(1, 0) != 7 {
+		t.Errorf("new edge B->A capacity = %d, want its cost 7", g.Capacity(1, 0))
+	}
+	// B->A has no explicit capacity, so it keeps following the cost.
+	if err := g.AddEdge("B", "A", 9); err != nil {
+		t.Fatal(err)
+	}
+	if g.Capacity(1, 0) != 9 {
+		t.Errorf("B->A capacity = %d, want the new cost 9", g.Capacity(1, 0))
+	}
+	want := []Edge{{From: "A", To: "B", Cost: 30, Capacity: 5}, {From: "B", To: "A", Cost: 9}}
+	if got := g.ToGraphJSON().Edges; !reflect.DeepEqual(got, want) {
+		t.Errorf("exported edges = %v, want %v", got, want)
+	}
+	// Derived graphs keep the distinction.
+	r := g.Reverse()
+	if err := r.AddEdge("A", "B", 4); err != nil {
+		t.Fatal(err)
+	}
+	if r.Capacity(0, 1) != 4 || r.Capacity(1, 0) != 5 {
+		t.Errorf("reversed capacities = %d, %d; want 4, 5", r.Capacity(0, 1), r.Capacity(1, 0))
+	}
+}
+
+func TestNewFromStruct_KeepSumCapacity(t *testing.T) {
+	gj := &GraphJSON{Edges: []Edge{
+		{From: "A", To: "B", Cost: 10}, {From: "A", To: "B", Cost: 20},
+		{From: "B", To: "C", Cost: 10, Capacity: 3}, {From: "B", To: "C", Cost: 20},
+	}}
+	g, err := NewFromStructWithOptions(gj, &Options{DuplicateEdges: KeepSum})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g.Capacity(0, 1) != 30 || g.Capacity(1, 2) != 23 {
+		t.Errorf("summed capacities = %d, %d; want 30, 23", g.Capacity(0, 1), g.Capacity(1, 2))
+	}
+	_ = g.AddEdge("A", "B", 40)
+	_ = g.AddEdge("B", "C", 40)
+	if g.Capacity(0, 1) != 40 || g.Capacity(1, 2) != 23 {
+		t.Errorf("after AddEdge: capacities = %d, %d; want 40, 23", g.Capacity(0, 1), g.Capacity(1, 2))
+	}
+}
+
+// TestConcurrentReadWrite is meant to be run with -race.
+func TestConcurrentReadWrite(t *testing.T) {
+	gj := &GraphJSON{Nodes: []string{"A", "B", "C", "D"}}
+	g, err := NewFromStruct(gj)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var wg sync.WaitGroup
+	for r := 0; r < 4; r++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for n := 0; n < 1000; n++ {
+				for i := 0; i < g.NumNodes(); i++ {
+					for _, j := range g.Neighbors(i) {
+						_ = g.Cost(i, j)
+					}
+				}
+			}
+		}()
+	}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for n := 0; n < 1000; n++ {
+			from, to := g.Name(n%4), g.Name((n+1)%4)
+			if err := g.AddEdge(from, to, n%MaxCost+1); err != nil {
+				t.Error(err)
+				return
+			}
+		}
+	}()
+	wg.Wait()
+}
+
+func TestReverse(t *testing.T) {
+	gj := &GraphJSON{
+		Nodes: []string{"A", "B", "C"},
+		Edges: []Edge{
+			{From: "A", To: "B", Cost: 10},
+			{From: "B", To: "C", Cost: 20},
+		},
+	}
+	g, _ := NewFromStruct(gj)
+	rev := g.Reverse()
+	for i, n := range g.Nodes {
+		if rev.Name(i) != n {
+			t.Fatalf("node order changed: %v vs %v", rev.Nodes, g.Nodes)
+		}
+	}
+	if rev.Cost(1, 0) != 10 || rev.Cost(2, 1) != 20 {
+		t.Errorf("reversed costs: B->A=%d C->B=%d", rev.Cost(1, 0), rev.Cost(2, 1))
+	}
+	if rev.Cost(0, 1) != 0 {
+		t.Errorf("A->B should not exist in reverse graph")
+	}
+}
+
+func TestClone(t *testing.T) {
+	g, _ := NewFromStruct(&GraphJSON{Edges: []Edge{{From: "A", To: "B", Cost: 10}}})
+	c := g.Clone()
+	if err := c.RemoveEdge("A", "B"); err != nil {
+		t.Fatal(err)
+	}
+	if g.Cost(0, 1) != 10 {
+		t.Errorf("original changed after mutating clone: %d", g.Cost(0, 1))
+	}
+}
+
+func TestNewFromStruct_StrictNodes(t *testing.T) {
+	gj := &GraphJSON{
+		Nodes: []string{"A", "B"},
+		Edges: []Edge{
+			{From: "A", To: "B", Cost: 10},
+			{From: "A", To: "b", Cost: 10}, // typo
+		},
+	}
+	if _, err := NewFromStructWithOptions(gj, &Options{StrictNodes: true}); err == nil {
+		t.Error("strict mode should reject edge to undeclared node b")
+	}
+	g, err := NewFromStructWithOptions(gj, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g.NumNodes() != 3 {
+		t.Errorf("permissive mode should infer node b: got %d nodes", g.NumNodes())
+	}
+}
+
+func TestCapacity(t *testing.T) {
+	g, err := NewFromStruct(&GraphJSON{
+		Edges: []Edge{
+			{From: "A", To: "B", Cost: 10, Capacity: 500},
+			{From: "B", To: "C", Cost: 20},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c := g.Capacity(0, 1); c != 500 {
+		t.Errorf("A->B capacity: got %d", c)
+	}
+	if c := g.Capacity(1, 2); c != 20 {
+		t.Errorf("B->C capacity should default to cost: got %d", c)
+	}
+	if c := g.Reverse().Capacity(1, 0); c != 500 {
+		t.Errorf("reversed B->A capacity: got %d", c)
+	}
+}
+
+func TestMeta_Roundtrip(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "graph.json")
+	src := `{"meta":{"region":"eu-west","ts":1700000000,"source":{"system":"nms","ver":2}},` +
+		`"nodes":["A","B"],"edges":[{"from":"A","to":"B","cost":5}]}`
+	if err := os.WriteFile(path, []byte(src), 0644); err != nil {
+		t.Fatal(err)
+	}
+	g, err := NewFromJSON(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g.Meta["region"] != "eu-west" {
+		t.Errorf("meta region: got %v", g.Meta["region"])
+	}
+	data, err := json.Marshal(g.ToGraphJSON())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var back GraphJSON
+	if err := json.Unmarshal(data, &back); err != nil {
+		t.Fatal(err)
+	}
+	var want map[string]any
+	_ = json.Unmarshal([]byte(src), &struct {
+		Meta *map[string]any `json:"meta"`
+	}{&want})
+	if !reflect.DeepEqual(back.Meta, want) {
+		t.Errorf("meta after round-trip: got %v, want %v", back.Meta, want)
+	}
+	if len(back.Edges) != 1 || back.Edges[0].Cost != 5 {
+		t.Errorf("edges after round-trip: %v", back.Edges)
+	}
+}
+
+func TestAttributes_Roundtrip(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "graph.json")
+	src := `{"nodes":["A","B"],"edges":[{"from":"A","to":"B","cost":5}],` +
+		`"attributes":{"A":{"region":"eu","asn":"64512"},"B":{"site":"pop"}}}`
+	if err := os.WriteFile(path, []byte(src), 0644); err != nil {
+		t.Fatal(err)
+	}
+	g, err := NewFromJSON(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := json.Marshal(g.Clone().ToGraphJSON())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var back GraphJSON
+	if err := json.Unmarshal(data, &back); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]map[string]string{"A": {"region": "eu", "asn": "64512"}, "B": {"site": "pop"}}
+	if !reflect.DeepEqual(back.Attributes, want) {
+		t.Errorf("attributes after round-trip: got %v, want %v", back.Attributes, want)
+	}
+}
+
+func TestNodeWeight(t *testing.T) {
+	gj := &GraphJSON{
+		Edges:      []Edge{{From: "A", To: "B", Cost: 1}, {From: "B", To: "C", Cost: 1}},
+		NodeWeight: map[string]int{"B": 5},
+	}
+	g, err := NewFromStruct(gj)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g.NodeWeight(1) != 5 || g.NodeWeight(0) != 0 {
+		t.Errorf("NodeWeight(B) = %d, NodeWeight(A) = %d; want 5, 0", g.NodeWeight(1), g.NodeWeight(0))
+	}
+	if got := g.Clone().ToGraphJSON().NodeWeight; !reflect.DeepEqual(got, gj.NodeWeight) {
+		t.Errorf("round trip: got %v, want %v", got, gj.NodeWeight)
+	}
+	if sub, _ := g.CopyWithoutNode(1); sub.NodeWeights != nil {
+		t.Errorf("CopyWithoutNode kept the removed node's weight: %v", sub.NodeWeights)
+	}
+	for _, bad := range []map[string]int{{"Z": 1}, {"B": -1}} {
+		gj.NodeWeight = bad
+		if _, err := NewFromStruct(gj); err == nil {
+			t.Errorf("expected error for node weights %v", bad)
+		}
+	}
+}
+
+func TestNodeAttr(t *testing.T) {
+	g, err := NewFromStruct(&GraphJSON{
+		Edges:      []Edge{{From: "A", To: "B", Cost: 5}},
+		Aliases:    map[string]string{"10.0.0.1": "A"},
+		Attributes: map[string]map[string]string{"10.0.0.1": {"region": "eu"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v, ok := g.NodeAttr("A", "region"); !ok || v != "eu" {
+		t.Errorf("A region = %q, %v; want eu (given by alias)", v, ok)
+	}
+	if _, ok := g.NodeAttr("A", "asn"); ok {
+		t.Error("A asn should be unset")
+	}
+	if _, ok := g.NodeAttr("B", "region"); ok {
+		t.Error("B has no attributes")
+	}
+	sub, _ := g.CopyWithoutNode(0)
+	if _, ok := sub.NodeAttr("A", "region"); ok {
+		t.Error("removed node's attributes should be dropped")
+	}
+	_, err = NewFromStruct(&GraphJSON{
+		Edges:      []Edge{{From: "A", To: "B", Cost: 5}},
+		Attributes: map[string]map[string]string{"Z": {"region": "eu"}},
+	})
+	if err == nil {
+		t.Error("expected error for attributes of an unknown node")
+	}
+}
+
+func TestMarshalJSONCanonical(t *testing.T) {
+	a, err := NewFromStruct(&GraphJSON{
+		Nodes:      []string{"C", "A", "B"},
+		Edges:      []Edge{{From: "C", To: "A", Cost: 3}, {From: "A", To: "B", Cost: 1, Weights: map[string]int{"latency": 9, "hops": 1}}},
+		NonTransit: []string{"C", "B"},
+		Meta:       map[string]any{"z": 1, "a": "x"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := NewFromStruct(&GraphJSON{
+		Nodes:      []string{"B", "C", "A"},
+		Edges:      []Edge{{From: "A", To: "B", Cost: 1, Weights: map[string]int{"hops": 1, "latency": 9}}, {From: "C", To: "A", Cost: 3}},
+		NonTransit: []string{"B", "C"},
+		Meta:       map[string]any{"a": "x", "z": 1},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ja, err := a.MarshalJSONCanonical()
+	if err != nil {
+		t.Fatal(err)
+	}
+	jb, err := b.MarshalJSONCanonical()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(ja, jb) {
+		t.Errorf("canonical JSON differs:\n%s\n%s", ja, jb)
+	}
+	var back GraphJSON
+	if err := json.Unmarshal(ja, &back); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(back.Nodes, []string{"A", "B", "C"}) || back.Edges[0].From != "A" {
+		t.Errorf("not sorted: nodes %v, edges %v", back.Nodes, back.Edges)
+	}
+}
+
+func TestIsolatedSourcesAndSinks(t *testing.T) {
+	// S only has out-edges (pure source), T only has in-edges (pure sink), M has both.
+	g, _ := NewFromStruct(&GraphJSON{
+		Edges: []Edge{
+			{From: "S", To: "M", Cost: 1},
+			{From: "M", To: "T", Cost: 1},
+		},
+	})
+	if got := g.IsolatedSources(); len(got) != 1 || got[0] != "T" {
+		t.Errorf("IsolatedSources (no out-edges): got %v, want [T]", got)
+	}
+	if got := g.IsolatedSinks(); len(got) != 1 || got[0] != "S" {
+		t.Errorf("IsolatedSinks (no in-edges): got %v, want [S]", got)
+	}
+}
+
+func TestNewFromStruct_Aliases(t *testing.T) {
+	gj := &GraphJSON{
+		Nodes: []string{"r1", "r2", "r3"},
+		Edges: []Edge{
+			{From: "r1", To: "r2", Cost: 10},
+			{From: "10.0.0.2", To: "r3", Cost: 20}, // alias of r2
+			{From: "r3", To: "10.0.0.1", Cost: 30}, // alias of r1
+		},
+		Aliases: map[string]string{"10.0.0.1": "r1", "10.0.0.2": "r2"},
+	}
+	g, err := NewFromStruct(gj)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g.NumNodes() != 3 {
+		t.Fatalf("aliases should not create nodes: got %v", g.Nodes)
+	}
+	r1, _ := g.Index("r1")
+	r2, _ := g.Index("r2")
+	r3, _ := g.Index("r3")
+	if g.Cost(r1, r2) != 10 || g.Cost(r2, r3) != 20 || g.Cost(r3, r1) != 30 {
+		t.Errorf("edges not coalesced onto canonical nodes: %v", g.AdjMatrix)
+	}
+	gj.Aliases["10.0.0.9"] = "r9"
+	if _, err := NewFromStruct(gj); err == nil {
+		t.Error("expected error for alias to unknown canonical node")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	g, _ := NewFromStruct(&GraphJSON{Edges: []Edge{{From: "A", To: "B", Cost: 1}}})
+	if err := g.Validate(); err != nil {
+		t.Errorf("constructed graph should be valid: %v", err)
+	}
+	bad := []*Graph{
+		{Nodes: []string{"A", "B"}, NameToIndex: map[string]int{"A": 0, "B": 1}, AdjMatrix: [][]int{{0, 1}, {0}}},
+		{Nodes: []string{"A", "B"}, NameToIndex: map[string]int{"A": 0, "B": 1}, AdjMatrix: [][]int{{0, 1}}},
+		{Nodes: []string{"A", "B"}, NameToIndex: map[string]int{"A": 1, "B": 0}, AdjMatrix: [][]int{{0, 1}, {0, 0}}},
+		{Nodes: []string{"A", "B"}, NameToIndex: map[string]int{"A": 0}, AdjMatrix: [][]int{{0, 1}, {0, 0}}},
+	}
+	for i, b := range bad {
+		if err := b.Validate(); err == nil {
+			t.Errorf("graph %d: expected validation error", i)
+		}
+	}
+}
+
+func TestWeightHistogram(t *testing.T) {
+	g, err := NewFromStruct(&GraphJSON{Edges: []Edge{
+		{From: "A", To: "B", Cost: 1},
+		{From: "B", To: "C", Cost: 100},
+		{From: "C", To: "D", Cost: 101},
+		{From: "D", To: "A", Cost: 550},
+		{From: "A", To: "C", Cost: 1000},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[int]int{1: 2, 101: 1, 501: 1, 901: 1}
+	if got := g.WeightHistogram(100); !reflect.DeepEqual(got, want) {
+		t.Errorf("WeightHistogram(100) = %v, want %v", got, want)
+	}
+	if got := g.WeightHistogram(0); got != nil {
+		t.Errorf("WeightHistogram(0) = %v, want nil", got)
+	}
+}
+
+func cycleGraph(t *testing.T, names ...string) *Graph {
+	t.Helper()
+	gj := &GraphJSON{}
+	for i, n := range names {
+		gj.Edges = append(gj.Edges, Edge{From: n, To: names[(i+1)%len(names)], Cost: 1})
+	}
+	g, err := NewFromStruct(gj)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return g
+}
+
+func TestIsBipartite(t *testing.T) {
+	ok, sides := cycleGraph(t, "A", "B", "C", "D").IsBipartite()
+	want := [2][]string{{"A", "C"}, {"B", "D"}}
+	if !ok || !reflect.DeepEqual(sides, want) {
+		t.Errorf("even cycle: got %v %v, want true %v", ok, sides, want)
+	}
+	ok, conflict := cycleGraph(t, "A", "B", "C").IsBipartite()
+	if ok {
+		t.Fatal("odd cycle: expected not bipartite")
+	}
+	// BFS from A colors B and C alike; the conflicting edge is B-C.
+	want = [2][]string{{"A", "B"}, {"A", "C"}}
+	if !reflect.DeepEqual(conflict, want) {
+		t.Errorf("odd cycle: conflict = %v, want %v", conflict, want)
+	}
+}
+
+func TestDuplicateEdgePolicy(t *testing.T) {
+	gj := &GraphJSON{Edges: []Edge{
+		{From: "A", To: "B", Cost: 30},
+		{From: "A", To: "B", Cost: 40},
+	}}
+	for _, tc := range []struct {
+		policy DuplicateEdgePolicy
+		want   int
+	}{
+		{KeepLast, 40},
+		{KeepSum, 70},
+	} {
+		g, err := NewFromStructWithOptions(gj, &Options{DuplicateEdges: tc.policy})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := g.Cost(0, 1); got != tc.want {
+			t.Errorf("policy %d: cost = %d, want %d", tc.policy, got, tc.want)
+		}
+	}
+	if _, err := NewFromStructWithOptions(gj, &Options{DuplicateEdges: DuplicateError}); err == nil {
+		t.Error("DuplicateError: expected error")
+	}
+	gj.Edges = append(gj.Edges, Edge{From: "A", To: "B", Cost: 950})
+	if _, err := NewFromStructWithOptions(gj, &Options{DuplicateEdges: KeepSum}); err == nil {
+		t.Error("KeepSum: expected error for summed cost above MaxCost")
+	}
+}
+
+func TestNewFromStructWithOptions_SizeLimits(t *testing.T) {
+	gj := &GraphJSON{Edges: []Edge{
+		{From: "A", To: "B", Cost: 1},
+		{From: "B", To: "C", Cost: 1},
+		{From: "C", To: "D", Cost: 1},
+	}}
+	if _, err := NewFromStructWithOptions(gj, &Options{MaxNodes: 3}); err == nil {
+		t.Error("MaxNodes 3: expected error for 4 nodes")
+	}
+	if _, err := NewFromStructWithOptions(gj, &Options{MaxEdges: 2}); err == nil {
+		t.Error("MaxEdges 2: expected error for 3 edges")
+	}
+	if _, err := NewFromStructWithOptions(gj, &Options{MaxNodes: 4, MaxEdges: 3}); err != nil {
+		t.Errorf("limits at the exact size: %v", err)
+	}
+}
+
+func TestNewFromStructWithOptions_Undirected(t *testing.T) {
+	gj := &GraphJSON{Edges: []Edge{
+		{From: "A", To: "B", Cost: 5},
+		{From: "B", To: "C", Cost: 7, OneWay: true},
+		{From: "C", To: "D", Cost: 2},
+		{From: "D", To: "C", Cost: 9},
+	}}
+	g, err := NewFromStructWithOptions(gj, &Options{Undirected: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range []struct {
+		from, to string
+		want     int
+	}{
+		{"A", "B", 5}, {"B", "A", 5},
+		{"B", "C", 7}, {"C", "B", 0},
+		{"C", "D", 2}, {"D", "C", 9}, // both directions given explicitly
+	} {
+		i, _ := g.Index(tc.from)
+		j, _ := g.Index(tc.to)
+		if got := g.Cost(i, j); got != tc.want {
+			t.Errorf("cost %s -> %s = %d, want %d", tc.from, tc.to, got, tc.want)
+		}
+	}
+}
+
+func TestNewFromStructWithOptions_WeightScale(t *testing.T) {
+	gj := &GraphJSON{Edges: []Edge{{From: "A", To: "B", Cost: 3}, {From: "B", To: "C", Cost: 600}}}
+	g, err := NewFromStructWithOptions(gj, &Options{WeightScale: 0.5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := g.Cost(0, 1); got != 2 {
+		t.Errorf("3 * 0.5 = %d, want 2 (halves round away from zero)", got)
+	}
+	if gj.Edges[0].Cost != 3 {
+		t.Error("scaling modified the input edges")
+	}
+	if _, err := NewFromStructWithOptions(gj, &Options{WeightScale: 2}); err == nil {
+		t.Error("expected error for a scaled cost above the maximum")
+	}
+	if _, err := NewFromStructWithOptions(gj, &Options{WeightScale: -1}); err == nil {
+		t.Error("expected error for a negative scale")
+	}
+}
+
+func TestNewFromStructWithOptions_SentinelWeight(t *testing.T) {
+	gj := &GraphJSON{
+		Nodes: []string{"A", "B", "C"},
+		Edges: []Edge{
+			{From: "A", To: "B", Cost: 10},
+			{From: "B", To: "C", Cost: 999999},
+			{From: "A", To: "C", Cost: 999999},
+		},
+	}
+	if _, err := NewFromStruct(gj); err == nil {
+		t.Fatal("expected the sentinel cost to be out of range without the option")
+	}
+	g, err := NewFromStructWithOptions(gj, &Options{SentinelWeight: 999999})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g.NumNodes() != 3 || g.Cost(0, 1) != 10 {
+		t.Fatalf("got %d nodes, A->B %d; want 3, 10", g.NumNodes(), g.Cost(0, 1))
+	}
+	if g.Cost(1, 2) != 0 || g.Cost(0, 2) != 0 {
+		t.Error("sentinel edges should not be in the adjacency")
+	}
+	if len(gj.Edges) != 3 {
+		t.Error("dropping sentinel edges modified the input")
+	}
+}
+
+func TestCopyWithoutNode_OnlyNode(t *testing.T) {
+	g, _ := NewFromStruct(&GraphJSON{Nodes: []string{"A"}})
+	sub, oldToNew := g.CopyWithoutNode(0)
+	if sub.NumNodes() != 0 || !reflect.DeepEqual(oldToNew, []int{-1}) {
+		t.Fatalf("got %d nodes, mapping %v", sub.NumNodes(), oldToNew)
+	}
+	if err := sub.Validate(); err != nil {
+		t.Errorf("empty subgraph should be valid: %v", err)
+	}
+	if empty, _ := sub.CopyWithoutNode(0); empty.NumNodes() != 0 {
+		t.Errorf("copy of an empty graph has %d nodes", empty.NumNodes())
+	}
+}
+
+func TestReachableWithin(t *testing.T) {
+	// Line A -> B -> C -> D -> E.
+	g, _ := NewFromStruct(&GraphJSON{Edges: []Edge{
+		{From: "A", To: "B", Cost: 10},
+		{From: "B", To: "C", Cost: 500},
+		{From: "C", To: "D", Cost: 1},
+		{From: "D", To: "E", Cost: 1},
+	}})
+	for _, tc := range []struct {
+		from string
+		hops int
+		want []string
+	}{
+		{"A", 2, []string{"A", "B", "C"}},
+		{"A", 0, []string{"A"}},
+		{"C", 10, []string{"C", "D", "E"}},
+		{"E", 3, []string{"E"}},
+	} {
+		got, err := g.ReachableWithin(tc.from, tc.hops)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("ReachableWithin(%s, %d) = %v, want %v", tc.from, tc.hops, got, tc.want)
+		}
+	}
+	if _, err := g.ReachableWithin("Z", 1); err == nil {
+		t.Error("expected error for unknown node")
+	}
+	if _, err := g.ReachableWithin("A", -1); err == nil {
+		t.Error("expected error for negative maxHops")
+	}
+}
+
+func TestIsWeaklyConnected(t *testing.T) {
+	// A -> B <- C is weakly but not strongly connected.
+	connected, _ := NewFromStruct(&GraphJSON{Edges: []Edge{{From: "A", To: "B", Cost: 1}, {From: "C", To: "B", Cost: 1}}})
+	if !connected.IsWeaklyConnected() {
+		t.Error("A -> B <- C should be weakly connected")
+	}
+	clusters, _ := NewFromStruct(&GraphJSON{Edges: []Edge{
+		{From: "A", To: "B", Cost: 1}, {From: "B", To: "A", Cost: 1},
+		{From: "C", To: "D", Cost: 1}, {From: "D", To: "E", Cost: 1},
+	}})
+	if clusters.IsWeaklyConnected() {
+		t.Error("{A, B} and {C, D, E} are separate clusters")
+	}
+	isolated, _ := NewFromStruct(&GraphJSON{Nodes: []string{"Z"}, Edges: []Edge{{From: "A", To: "B", Cost: 1}}})
+	if isolated.IsWeaklyConnected() {
+		t.Error("isolated node Z should disconnect the graph")
+	}
+}
+
+func TestSortedNodes(t *testing.T) {
+	a, _ := NewFromStruct(&GraphJSON{Edges: []Edge{{From: "C", To: "A", Cost: 1}, {From: "B", To: "C", Cost: 1}}})
+	b, _ := NewFromStruct(&GraphJSON{Nodes: []string{"B", "A", "C"}})
+	want := []string{"A", "B", "C"}
+	if got := a.SortedNodes(); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	if got := b.SortedNodes(); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	if a.Nodes[0] != "C" {
+		t.Error("SortedNodes modified Nodes")
+	}
+}
+
+func TestWeightRangeWarnings(t *testing.T) {
+	g, err := NewFromStruct(&GraphJSON{Edges: []Edge{
+		{From: "A", To: "B", Cost: 1},
+		{From: "A", To: "C", Cost: 1000},
+		{From: "B", To: "C", Cost: 50},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := g.WeightRangeWarnings(100)
+	want := []string{
+		"graph: edge costs range from 1 to 1000, ratio 1000 > 100",
+		"node A: outgoing costs range from 1 (to B) to 1000 (to C), ratio 1000 > 100",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if got := g.WeightRangeWarnings(1000); got != nil {
+		t.Errorf("ratio 1000: got %q, want none", got)
+	}
+}
+
+func TestCycles(t *testing.T) {
+	g, err := NewFromStruct(&GraphJSON{Edges: []Edge{
+		{From: "A", To: "B", Cost: 1},
+		{From: "B", To: "C", Cost: 1},
+		{From: "C", To: "A", Cost: 1},
+		{From: "C", To: "D", Cost: 1},
+		{From: "A", To: "D", Cost: 1},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := [][]string{{"A", "B", "C"}}
+	if got := g.Cycles(0); !reflect.DeepEqual(got, want) {
+		t.Errorf("Cycles(0) = %v, want %v", got, want)
+	}
+	if got := g.Cycles(1); !reflect.DeepEqual(got, want) {
+		t.Errorf("Cycles(1) = %v, want %v", got, want)
+	}
+
+	if err := g.AddEdge("D", "B", 1); err != nil {
+		t.Fatal(err)
+	}
+	// A->B->C->A, B->C->D->B and A->D->B->C->A.
+	if got := g.Cycles(0); len(got) != 3 {
+		t.Errorf("after adding D->B: got %d cycles %v, want 3", len(got), got)
+	}
+	if got := g.Cycles(2); len(got) != 2 {
+		t.Errorf("Cycles(2) returned %d cycles", len(got))
+	}
+}
+
+func TestWeighted_SharesGraphMethods(t *testing.T) {
+	var _ *Weighted[int] = (*Graph)(nil) // Graph is an alias, not a separate type
+	g, err := NewWeighted(nil, []WeightedEdge[float64]{
+		{From: "A", To: "B", Weight: 0.5},
+		{From: "B", To: "C", Weight: 0.25},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := g.Neighbors(0); !reflect.DeepEqual(got, []int{1}) {
+		t.Errorf("Neighbors(A) = %v, want [1]", got)
+	}
+	// NewWeighted graphs accept any positive cost, not just [MinCost, MaxCost].
+	if err := g.AddEdge("C", "A", 0.125); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.AddEdge("A", "C", -1); err == nil {
+		t.Error("expected error for a non-positive cost")
+	}
+	r := g.Reverse()
+	if r.Cost(0, 2) != 0.125 || r.Capacity(1, 0) != 0.5 {
+		t.Errorf("Reverse: A->C %v, capacity B->A %v; want 0.125 and 0.5", r.Cost(0, 2), r.Capacity(1, 0))
+	}
+	if err := r.Clone().AddEdge("A", "B", 0.75); err != nil {
+		t.Errorf("clone lost the unbounded cost range: %v", err)
+	}
+	u, err := NewWeighted(nil, []WeightedEdge[uint8]{{From: "A", To: "B", Weight: 7}})
+	if err != nil || u.Cost(0, 1) != 7 {
+		t.Errorf("uint8 graph: cost %v, err %v", u.Cost(0, 1), err)
+	}
+}
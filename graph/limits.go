@@ -0,0 +1,38 @@
+package graph
+
+// Default path caps used by the floyd package; see Limits.
+const (
+	DefaultMaxShortestPaths    = 4
+	DefaultMaxViaNeighborPaths = 3
+)
+
+// Limits gathers the tunable bounds of graph construction and path enumeration in one place.
+// A zero field (or a nil *Limits) means the default: MinCost, MaxCost,
+// DefaultMaxShortestPaths and DefaultMaxViaNeighborPaths respectively.
+type Limits struct {
+	MinCost             int // smallest accepted edge cost or metric value
+	MaxCost             int // largest accepted edge cost or metric value
+	MaxShortestPaths    int // paths kept per pair in PairResult.Paths
+	MaxViaNeighborPaths int // paths kept per pair in PairResult.ViaNeighborPaths
+}
+
+// Resolved returns a copy of l with every zero field replaced by its default; l may be nil.
+func (l *Limits) Resolved() Limits {
+	var r Limits
+	if l != nil {
+		r = *l
+	}
+	if r.MinCost == 0 {
+		r.MinCost = MinCost
+	}
+	if r.MaxCost == 0 {
+		r.MaxCost = MaxCost
+	}
+	if r.MaxShortestPaths == 0 {
+		r.MaxShortestPaths = DefaultMaxShortestPaths
+	}
+	if r.MaxViaNeighborPaths == 0 {
+		r.MaxViaNeighborPaths = DefaultMaxViaNeighborPaths
+	}
+	return r
+}
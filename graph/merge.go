@@ -0,0 +1,74 @@
+package graph
+
+import "fmt"
+
+// Merge combines several GraphJSON parts into one. Nodes keep their first-seen order; an edge
+// given by more than one part is kept once if every copy has the same cost and is an error
+// otherwise. Meta keys from later parts override earlier ones; an alias mapped to different
+// names by two parts is an error; NonTransit lists are concatenated and node Attributes are
+// combined key by key, later parts overriding earlier ones. A node given different NodeWeight
+// values by two parts is an error. Edges are not validated here; NewFromStruct does that.
+func Merge(parts ...*GraphJSON) (*GraphJSON, error) {
+	out := &GraphJSON{}
+	seenNode := make(map[string]bool)
+	edgeAt := make(map[[2]string]int) // index into out.Edges
+	for _, p := range parts {
+		if p == nil {
+			continue
+		}
+		for _, n := range p.Nodes {
+			if !seenNode[n] {
+				seenNode[n] = true
+				out.Nodes = append(out.Nodes, n)
+			}
+		}
+		for _, e := range p.Edges {
+			key := [2]string{e.From, e.To}
+			if k, ok := edgeAt[key]; ok {
+				if out.Edges[k].Cost != e.Cost {
+					return nil, fmt.Errorf("conflicting costs for edge %s -> %s: %d and %d", e.From, e.To, out.Edges[k].Cost, e.Cost)
+				}
+				continue
+			}
+			edgeAt[key] = len(out.Edges)
+			out.Edges = append(out.Edges, e)
+		}
+		out.NonTransit = append(out.NonTransit, p.NonTransit...)
+		for n, attrs := range p.Attributes {
+			if out.Attributes == nil {
+				out.Attributes = make(map[string]map[string]string)
+			}
+			if out.Attributes[n] == nil {
+				out.Attributes[n] = make(map[string]string, len(attrs))
+			}
+			for k, v := range attrs {
+				out.Attributes[n][k] = v
+			}
+		}
+		for n, w := range p.NodeWeight {
+			if prev, ok := out.NodeWeight[n]; ok && prev != w {
+				return nil, fmt.Errorf("conflicting weights for node %q: %d and %d", n, prev, w)
+			}
+			if out.NodeWeight == nil {
+				out.NodeWeight = make(map[string]int)
+			}
+			out.NodeWeight[n] = w
+		}
+		for k, v := range p.Meta {
+			if out.Meta == nil {
+				out.Meta = make(map[string]any)
+			}
+			out.Meta[k] = v
+		}
+		for alias, name := range p.Aliases {
+			if prev, ok := out.Aliases[alias]; ok && prev != name {
+				return nil, fmt.Errorf("alias %q maps to both %q and %q", alias, prev, name)
+			}
+			if out.Aliases == nil {
+				out.Aliases = make(map[string]string)
+			}
+			out.Aliases[alias] = name
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,42 @@
+package graph
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestNewFromDir(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("a.json", `{"nodes":["A"],"edges":[{"from":"A","to":"B","cost":50},{"from":"A","to":"C","cost":100}]}`)
+	write("b.json", `{"nodes":[{"nodeId":"B"}],"edges":[{"from":"B","to":"C","cost":20},{"from":"A","to":"B","cost":50}]}`)
+	write("notes.txt", "ignored")
+	g, err := NewFromDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g.NumNodes() != 3 {
+		t.Fatalf("got %d nodes, want 3: %v", g.NumNodes(), g.Nodes)
+	}
+	for _, e := range []Edge{{From: "A", To: "B", Cost: 50}, {From: "A", To: "C", Cost: 100}, {From: "B", To: "C", Cost: 20}} {
+		i, _ := g.Index(e.From)
+		j, _ := g.Index(e.To)
+		if got := g.Cost(i, j); got != e.Cost {
+			t.Errorf("%s -> %s cost = %d, want %d", e.From, e.To, got, e.Cost)
+		}
+	}
+
+	write("c.json", `{"edges":[{"from":"B","to":"C","cost":25}]}`)
+	if _, err := NewFromDir(dir); err == nil {
+		t.Error("expected error for conflicting B -> C costs")
+	}
+	if _, err := NewFromDir(t.TempDir()); err == nil {
+		t.Error("expected error for a directory without JSON files")
+	}
+}
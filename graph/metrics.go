@@ -0,0 +1,55 @@
+package graph
+
+import (
+	"fmt"
+	"sort"
+)
+
+// CostMetric names the primary edge cost (Edge.Cost / AdjMatrix) among the metrics.
+const CostMetric = "cost"
+
+// MetricNames returns the sorted names of the extra metrics carried by g's edges, not including
+// CostMetric.
+func (g *Weighted[W]) MetricNames() []string {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	names := make([]string, 0, len(g.Metrics))
+	for name := range g.Metrics {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// MetricValue returns the value of metric on edge i->j; "" and CostMetric mean the cost. 0 means
+// the edge does not exist or does not carry the metric.
+func (g *Weighted[W]) MetricValue(metric string, i, j int) W {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	if metric == "" || metric == CostMetric {
+		return g.AdjMatrix[i][j]
+	}
+	if m, ok := g.Metrics[metric]; ok {
+		return m[i][j]
+	}
+	return 0
+}
+
+// MetricGraph returns a copy of g whose costs are the values of metric, so the usual algorithms
+// optimize for it instead of the cost. "" and CostMetric return a plain clone. Edges that do not
+// carry the metric are absent from the copy; capacities fall back to the new costs and node
+// weights, which are in cost units, are dropped.
+func (g *Weighted[W]) MetricGraph(metric string) (*Weighted[W], error) {
+	c := g.Clone()
+	if metric == "" || metric == CostMetric {
+		return c, nil
+	}
+	m, ok := c.Metrics[metric]
+	if !ok {
+		return nil, fmt.Errorf("unknown metric %q", metric)
+	}
+	c.AdjMatrix = copyMatrix(m)
+	c.CapMatrix = nil
+	c.NodeWeights = nil
+	return c, nil
+}
@@ -0,0 +1,48 @@
+package graph
+
+// SymmetryReport returns the edges that have no edge in the reverse direction, in node index
+// order, with their cost and capacity. For a graph meant to be undirected these are the pairs
+// that were only given one way, which makes routing subtly directed.
+func (g *Weighted[W]) SymmetryReport() []Edge {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	var out []Edge
+	for i, row := range g.AdjMatrix {
+		for j, w := range row {
+			if w == 0 || g.AdjMatrix[j][i] != 0 {
+				continue
+			}
+			e := Edge{From: g.Nodes[i], To: g.Nodes[j], Cost: int(w)}
+			if c := g.capacity(i, j); c != w {
+				e.Capacity = int(c)
+			}
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+// Symmetrize returns a copy of g with every edge listed by SymmetryReport added in the reverse
+// direction, copying its cost, capacity and metric weights. Existing reverse edges are kept as
+// they are, even if their cost differs.
+func (g *Weighted[W]) Symmetrize() *Weighted[W] {
+	c := g.Clone()
+	N := len(c.Nodes)
+	for i := 0; i < N; i++ {
+		for j := 0; j < N; j++ {
+			// Once j->i is added here, the later visit of (j, i) sees both directions and skips.
+			w := c.AdjMatrix[i][j]
+			if w == 0 || c.AdjMatrix[j][i] != 0 {
+				continue
+			}
+			c.AdjMatrix[j][i] = w
+			if c.CapMatrix != nil {
+				c.CapMatrix[j][i] = c.CapMatrix[i][j]
+			}
+			for _, m := range c.Metrics {
+				m[j][i] = m[i][j]
+			}
+		}
+	}
+	return c
+}
@@ -0,0 +1,33 @@
+package graph
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSymmetryReportAndSymmetrize(t *testing.T) {
+	g, err := NewFromStruct(&GraphJSON{Edges: []Edge{
+		{From: "A", To: "B", Cost: 10},
+		{From: "B", To: "A", Cost: 10},
+		{From: "B", To: "C", Cost: 30, Capacity: 5},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Edge{{From: "B", To: "C", Cost: 30, Capacity: 5}}
+	if got := g.SymmetryReport(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("SymmetryReport = %v, want %v", got, want)
+	}
+	s := g.Symmetrize()
+	if got := s.SymmetryReport(); got != nil {
+		t.Errorf("symmetrized graph still reports %v", got)
+	}
+	b, _ := s.Index("B")
+	c, _ := s.Index("C")
+	if s.Cost(c, b) != 30 || s.Capacity(c, b) != 5 {
+		t.Errorf("C->B = cost %d capacity %d, want 30 and 5", s.Cost(c, b), s.Capacity(c, b))
+	}
+	if g.Cost(c, b) != 0 {
+		t.Error("Symmetrize modified the original graph")
+	}
+}
@@ -0,0 +1,50 @@
+package graph
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Snapshot is the graph in effect from Time until the next snapshot.
+type Snapshot struct {
+	Time  time.Time
+	Graph *Graph
+}
+
+// TemporalGraph holds time-ordered snapshots of a graph whose costs vary by time window. Route on
+// the graph returned by At as usual.
+type TemporalGraph struct {
+	snaps []Snapshot
+}
+
+// NewTemporalGraph returns a TemporalGraph over snaps, which may be given in any order. Two
+// snapshots at the same time or a nil graph are errors.
+func NewTemporalGraph(snaps ...Snapshot) (*TemporalGraph, error) {
+	sorted := append([]Snapshot(nil), snaps...)
+	sort.SliceStable(sorted, func(a, b int) bool { return sorted[a].Time.Before(sorted[b].Time) })
+	for k, s := range sorted {
+		if s.Graph == nil {
+			return nil, fmt.Errorf("snapshot at %s has no graph", s.Time.Format(time.RFC3339))
+		}
+		if k > 0 && s.Time.Equal(sorted[k-1].Time) {
+			return nil, fmt.Errorf("two snapshots at %s", s.Time.Format(time.RFC3339))
+		}
+	}
+	return &TemporalGraph{snaps: sorted}, nil
+}
+
+// At returns the graph effective at t: the most recent snapshot at or before t, or nil if t is
+// before the first snapshot.
+func (tg *TemporalGraph) At(t time.Time) *Graph {
+	k := sort.Search(len(tg.snaps), func(k int) bool { return tg.snaps[k].Time.After(t) })
+	if k == 0 {
+		return nil
+	}
+	return tg.snaps[k-1].Graph
+}
+
+// Snapshots returns the snapshots in time order.
+func (tg *TemporalGraph) Snapshots() []Snapshot {
+	return append([]Snapshot(nil), tg.snaps...)
+}
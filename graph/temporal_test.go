@@ -0,0 +1,37 @@
+package graph
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTemporalGraph_At(t *testing.T) {
+	day := mustNew(&GraphJSON{Edges: []Edge{{From: "A", To: "B", Cost: 10}}})
+	night := mustNew(&GraphJSON{Edges: []Edge{{From: "A", To: "B", Cost: 50}}})
+	t0 := time.Date(2024, 1, 1, 8, 0, 0, 0, time.UTC)
+	t1 := time.Date(2024, 1, 1, 20, 0, 0, 0, time.UTC)
+	tg, err := NewTemporalGraph(Snapshot{t1, night}, Snapshot{t0, day})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range []struct {
+		at   time.Time
+		want *Graph
+	}{
+		{t0.Add(-time.Nanosecond), nil},
+		{t0, day},
+		{t1.Add(-time.Nanosecond), day},
+		{t1, night},
+		{t1.Add(24 * time.Hour), night},
+	} {
+		if got := tg.At(tc.at); got != tc.want {
+			t.Errorf("At(%s): got %p, want %p", tc.at.Format(time.RFC3339Nano), got, tc.want)
+		}
+	}
+	if _, err := NewTemporalGraph(Snapshot{t0, day}, Snapshot{t0, night}); err == nil {
+		t.Error("expected error for two snapshots at the same time")
+	}
+	if _, err := NewTemporalGraph(Snapshot{t0, nil}); err == nil {
+		t.Error("expected error for a nil graph")
+	}
+}
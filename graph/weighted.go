@@ -0,0 +1,51 @@
+package graph
+
+import "fmt"
+
+// Weight is the set of numeric edge cost types a Weighted graph may use: every integer and
+// floating-point type, like constraints.Integer | constraints.Float.
+type Weight interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr |
+		~float32 | ~float64
+}
+
+// WeightedEdge is a directed edge with a weight of type W.
+type WeightedEdge[W Weight] struct {
+	From   string `json:"from"`
+	To     string `json:"to"`
+	Weight W      `json:"weight"`
+}
+
+// NewWeighted builds a graph with weights of type W, e.g. Weighted[float64] for fractional
+// latencies that do not fit Graph's integer cost range. Nodes missing from nodes are inferred
+// from edges in first-seen order, like NewFromStruct. Weights must be positive; no upper bound
+// applies unless Limits is set on the result.
+func NewWeighted[W Weight](nodes []string, edges []WeightedEdge[W]) (*Weighted[W], error) {
+	var order []string
+	nameToIndex := make(map[string]int)
+	add := func(n string) {
+		if _, ok := nameToIndex[n]; !ok {
+			nameToIndex[n] = len(order)
+			order = append(order, n)
+		}
+	}
+	for _, n := range nodes {
+		add(n)
+	}
+	for _, e := range edges {
+		if !(e.Weight > 0) {
+			return nil, fmt.Errorf("edge %s -> %s weight %v must be positive", e.From, e.To, e.Weight)
+		}
+		add(e.From)
+		add(e.To)
+	}
+	if len(order) == 0 {
+		return nil, fmt.Errorf("graph has no nodes")
+	}
+	adj := newMatrix[W](len(order))
+	for _, e := range edges {
+		adj[nameToIndex[e.From]][nameToIndex[e.To]] = e.Weight
+	}
+	return &Weighted[W]{Nodes: order, NameToIndex: nameToIndex, AdjMatrix: adj, unbounded: true}, nil
+}